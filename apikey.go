@@ -0,0 +1,337 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrAPIKeyNotFound is returned by an APIKeyStore that has no key with the requested ID
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+const (
+	// apiKeySecretSize is the number of random bytes in an API key's secret
+	apiKeySecretSize = 32
+
+	// apiKeyTouchInterval is how stale an API key's last use may get before it is written to the store again
+	apiKeyTouchInterval = time.Minute
+)
+
+// apiKeyEncoding encodes the parts of an API key. It has no "_", so the parts can be told apart
+var apiKeyEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+var (
+	apiKeyPrefix  = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+	apiKeyPattern = regexp.MustCompile(`^([a-z0-9]+(?:_[a-z0-9]+)*)_([a-z2-7]{26})_([a-z2-7]{52})_([a-z2-7]{7})$`)
+)
+
+// APIKey is the stored state of one API key. Only a hash of the key's secret is kept
+type APIKey struct {
+	ID         uuid.UUID  `json:"id"`
+	Hash       string     `json:"hash"`
+	Username   string     `json:"username"`
+	Scopes     []string   `json:"scopes,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // nil until the key is first used
+}
+
+// APIKeyStore persists API keys for an APIKeyManager
+type APIKeyStore interface {
+
+	// Save stores a newly generated API key
+	Save(ctx context.Context, key APIKey) error
+
+	// Get returns the API key with the given ID, or ErrAPIKeyNotFound
+	Get(ctx context.Context, id uuid.UUID) (*APIKey, error)
+
+	// Touch records that the API key with the given ID was used at usedAt. VerifyKey ignores its errors
+	Touch(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+
+	// Delete removes the API key with the given ID, it is rejected from then on
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// List returns the API keys of a user
+	List(ctx context.Context, username string) ([]APIKey, error)
+}
+
+// APIKeyManager issues and verifies opaque API keys for long-lived machine credentials.
+// Keys look like "<prefix>_<id>_<secret>_<checksum>", e.g. "tok_live_...". The checksum lets typos and
+// leaked-secret scanners recognise a key without the store, which only holds a hash of the secret.
+// An APIKeyManager is a Maker, so a verified key yields a Payload like any other token
+type APIKeyManager struct {
+	prefix string
+	store  APIKeyStore
+	options
+}
+
+// NewAPIKeyManager creates a manager for keys starting with prefix (lowercase letters, digits and
+// underscores, e.g. "tok_live"). WithClock and WithLeeway apply to the keys' expiry
+func NewAPIKeyManager(prefix string, store APIKeyStore, opts ...Option) (*APIKeyManager, error) {
+	if !apiKeyPrefix.MatchString(prefix) {
+		return nil, fmt.Errorf("invalid API key prefix %q", prefix)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("API key store is required")
+	}
+	return &APIKeyManager{prefix: prefix, store: store, options: newOptions(opts)}, nil
+}
+
+// CreateToken generates an API key without scopes for username, valid for duration
+func (manager *APIKeyManager) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return manager.CreateTokenContext(context.Background(), username, duration)
+}
+
+// VerifyToken verifies an API key
+func (manager *APIKeyManager) VerifyToken(token string) (*Payload, error) {
+	return manager.VerifyKey(context.Background(), token)
+}
+
+// CreateTokenContext generates an API key without scopes for username, valid for duration
+func (manager *APIKeyManager) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	key, stored, err := manager.GenerateKey(ctx, username, nil, duration)
+	if err != nil {
+		return "", nil, err
+	}
+	return key, stored.payload(), nil
+}
+
+// VerifyTokenContext verifies an API key, it is the same as VerifyKey
+func (manager *APIKeyManager) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return manager.VerifyKey(ctx, token)
+}
+
+// GenerateKey generates an API key for username carrying the given scopes, valid for duration.
+// The key is only returned here, the store keeps a hash of its secret
+func (manager *APIKeyManager) GenerateKey(ctx context.Context, username string, scopes []string, duration time.Duration) (string, *APIKey, error) {
+	if duration <= 0 {
+		return "", nil, fmt.Errorf("API key duration must be positive")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, apiKeySecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("could not generate API key: %w", err)
+	}
+	encodedSecret := apiKeyEncoding.EncodeToString(secret)
+
+	now := manager.now()
+	stored := APIKey{
+		ID:        id,
+		Hash:      hashAPIKeySecret(encodedSecret),
+		Username:  username,
+		Scopes:    append([]string(nil), scopes...),
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+	if err := manager.store.Save(ctx, stored); err != nil {
+		return "", nil, fmt.Errorf("could not save API key: %w", err)
+	}
+
+	body := manager.prefix + "_" + apiKeyEncoding.EncodeToString(id[:]) + "_" + encodedSecret
+	return body + "_" + apiKeyChecksum(body), &stored, nil
+}
+
+// VerifyKey checks an API key against the store and returns its payload. The key's ID becomes the payload's ID,
+// and its scopes the payload's scopes
+func (manager *APIKeyManager) VerifyKey(ctx context.Context, key string) (*Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	id, secret, err := manager.parseKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := manager.store.Get(ctx, id)
+	if errors.Is(err, ErrAPIKeyNotFound) {
+		return nil, newVerificationError(ReasonUnknownKey, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load API key: %w", err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(stored.Hash)) != 1 {
+		return nil, newVerificationError(ReasonBadSignature, nil)
+	}
+
+	payload := stored.payload()
+	if err := manager.options.verify(payload); err != nil {
+		return nil, err
+	}
+
+	if now := manager.now(); stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= apiKeyTouchInterval {
+		// the last use is only bookkeeping, a store failing to record it mustn't reject a valid key
+		_ = manager.store.Touch(ctx, id, now)
+	}
+
+	return payload, nil
+}
+
+// Revoke deletes an API key, it is rejected from then on
+func (manager *APIKeyManager) Revoke(ctx context.Context, id uuid.UUID) error {
+	return manager.store.Delete(ctx, id)
+}
+
+// List returns the API keys of a user
+func (manager *APIKeyManager) List(ctx context.Context, username string) ([]APIKey, error) {
+	return manager.store.List(ctx, username)
+}
+
+// parseKey checks an API key's prefix and checksum and returns its ID and secret, without looking it up
+func (manager *APIKeyManager) parseKey(key string) (uuid.UUID, string, error) {
+	match := apiKeyPattern.FindStringSubmatch(key)
+	if match == nil || match[1] != manager.prefix {
+		return uuid.Nil, "", newVerificationError(ReasonMalformed, nil)
+	}
+
+	body := strings.TrimSuffix(key, "_"+match[4])
+	if subtle.ConstantTimeCompare([]byte(apiKeyChecksum(body)), []byte(match[4])) != 1 {
+		return uuid.Nil, "", newVerificationError(ReasonMalformed, fmt.Errorf("API key checksum mismatch"))
+	}
+
+	idBytes, err := apiKeyEncoding.DecodeString(match[2])
+	if err != nil {
+		return uuid.Nil, "", newVerificationError(ReasonMalformed, err)
+	}
+	id, err := uuid.FromBytes(idBytes)
+	if err != nil {
+		return uuid.Nil, "", newVerificationError(ReasonMalformed, err)
+	}
+
+	return id, match[3], nil
+}
+
+// payload returns the Payload a verified API key stands for
+func (key APIKey) payload() *Payload {
+	return &Payload{
+		ID:        key.ID,
+		Username:  key.Username,
+		IssuedAt:  key.CreatedAt,
+		NotBefore: key.CreatedAt,
+		ExpiredAt: key.ExpiresAt,
+		Scopes:    append(Scopes(nil), key.Scopes...),
+	}
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyChecksum is the CRC-32 of everything in the key before it
+func apiKeyChecksum(body string) string {
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE([]byte(body)))
+	return apiKeyEncoding.EncodeToString(sum[:])
+}
+
+// MemoryAPIKeyStore is an in-process APIKeyStore, meant for tests and single instance services
+type MemoryAPIKeyStore struct {
+	mu   sync.Mutex
+	keys map[uuid.UUID]APIKey
+}
+
+// NewMemoryAPIKeyStore creates an empty in-memory API key store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{keys: make(map[uuid.UUID]APIKey)}
+}
+
+// Save stores a newly generated API key
+func (store *MemoryAPIKeyStore) Save(_ context.Context, key APIKey) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.keys[key.ID] = key.clone()
+	return nil
+}
+
+// Get returns the API key with the given ID
+func (store *MemoryAPIKeyStore) Get(_ context.Context, id uuid.UUID) (*APIKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	key, ok := store.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	key = key.clone()
+	return &key, nil
+}
+
+// Touch records the last use of an API key
+func (store *MemoryAPIKeyStore) Touch(_ context.Context, id uuid.UUID, usedAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	key, ok := store.keys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &usedAt
+	store.keys[id] = key
+	return nil
+}
+
+// Delete removes an API key
+func (store *MemoryAPIKeyStore) Delete(_ context.Context, id uuid.UUID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delete(store.keys, id)
+	return nil
+}
+
+// List returns the API keys of a user, oldest first
+func (store *MemoryAPIKeyStore) List(_ context.Context, username string) ([]APIKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return listAPIKeys(store.keys, username), nil
+}
+
+// listAPIKeys returns copies of the keys belonging to username, oldest first
+func listAPIKeys(keys map[uuid.UUID]APIKey, username string) []APIKey {
+	var list []APIKey
+	for _, key := range keys {
+		if key.Username == username {
+			list = append(list, key.clone())
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// clone copies a key, so callers can't modify a stored key's scopes
+func (key APIKey) clone() APIKey {
+	key.Scopes = append([]string(nil), key.Scopes...)
+	if key.LastUsedAt != nil {
+		lastUsedAt := *key.LastUsedAt
+		key.LastUsedAt = &lastUsedAt
+	}
+	return key
+}
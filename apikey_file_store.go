@@ -0,0 +1,159 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileAPIKeyStore is an APIKeyStore kept in a JSON file, for services without a database.
+// The file is read once when the store is opened and rewritten atomically on every change
+type FileAPIKeyStore struct {
+	mu   sync.Mutex
+	path string
+	keys map[uuid.UUID]APIKey
+}
+
+// NewFileAPIKeyStore opens the API key store kept in the file at path, which is created on the first change
+func NewFileAPIKeyStore(path string) (*FileAPIKeyStore, error) {
+	store := &FileAPIKeyStore{path: path, keys: make(map[uuid.UUID]APIKey)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read API key store: %w", err)
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("could not decode API key store: %w", err)
+	}
+	for _, key := range keys {
+		store.keys[key.ID] = key
+	}
+
+	return store, nil
+}
+
+// Save stores a newly generated API key
+func (store *FileAPIKeyStore) Save(_ context.Context, key APIKey) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	keys := store.copyKeys()
+	keys[key.ID] = key.clone()
+	return store.write(keys)
+}
+
+// Get returns the API key with the given ID
+func (store *FileAPIKeyStore) Get(_ context.Context, id uuid.UUID) (*APIKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	key, ok := store.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotFound
+	}
+	key = key.clone()
+	return &key, nil
+}
+
+// Touch records the last use of an API key
+func (store *FileAPIKeyStore) Touch(_ context.Context, id uuid.UUID, usedAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	key, ok := store.keys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	key.LastUsedAt = &usedAt
+
+	keys := store.copyKeys()
+	keys[id] = key
+	return store.write(keys)
+}
+
+// Delete removes an API key
+func (store *FileAPIKeyStore) Delete(_ context.Context, id uuid.UUID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if _, ok := store.keys[id]; !ok {
+		return nil
+	}
+
+	keys := store.copyKeys()
+	delete(keys, id)
+	return store.write(keys)
+}
+
+// List returns the API keys of a user, oldest first
+func (store *FileAPIKeyStore) List(_ context.Context, username string) ([]APIKey, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return listAPIKeys(store.keys, username), nil
+}
+
+// copyKeys returns a copy of the keys to apply a change to, the caller must hold the lock
+func (store *FileAPIKeyStore) copyKeys() map[uuid.UUID]APIKey {
+	keys := make(map[uuid.UUID]APIKey, len(store.keys)+1)
+	for id, key := range store.keys {
+		keys[id] = key
+	}
+	return keys
+}
+
+// write replaces the file with keys and only then makes them the store's keys, so a failed write leaves the
+// store as it was. The caller must hold the lock
+func (store *FileAPIKeyStore) write(keys map[uuid.UUID]APIKey) error {
+	list := make([]APIKey, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID.String() < list[j].ID.String()
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file next to the store and rename it over, so readers never see a partial file
+	file, err := os.CreateTemp(filepath.Dir(store.path), filepath.Base(store.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not write API key store: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("could not write API key store: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("could not write API key store: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("could not write API key store: %w", err)
+	}
+	if err := os.Rename(file.Name(), store.path); err != nil {
+		return fmt.Errorf("could not write API key store: %w", err)
+	}
+
+	store.keys = keys
+	return nil
+}
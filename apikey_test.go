@@ -0,0 +1,209 @@
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyManager(t *testing.T) {
+	ctx := context.Background()
+
+	stores := map[string]func(t *testing.T) APIKeyStore{
+		"Memory": func(t *testing.T) APIKeyStore {
+			return NewMemoryAPIKeyStore()
+		},
+		"File": func(t *testing.T) APIKeyStore {
+			store, err := NewFileAPIKeyStore(filepath.Join(t.TempDir(), "keys.json"))
+			require.NoError(t, err)
+			return store
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			manager, err := NewAPIKeyManager("tok_live", store)
+			require.NoError(t, err)
+
+			key, stored, err := manager.GenerateKey(ctx, "ci-bot", []string{"orders:read", "orders:write"}, 24*time.Hour)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(key, "tok_live_"))
+			require.NotContains(t, stored.Hash, key)
+
+			t.Run("Verify", func(t *testing.T) {
+				payload, err := manager.VerifyKey(ctx, key)
+				require.NoError(t, err)
+				require.Equal(t, stored.ID, payload.ID)
+				require.Equal(t, "ci-bot", payload.Username)
+				require.WithinDuration(t, stored.ExpiresAt, payload.ExpiredAt, time.Second)
+
+				require.Equal(t, Scopes{"orders:read", "orders:write"}, payload.Scopes)
+				require.NoError(t, payload.RequireScopes("orders:write"))
+
+				loaded, err := store.Get(ctx, stored.ID)
+				require.NoError(t, err)
+				require.NotNil(t, loaded.LastUsedAt)
+			})
+
+			t.Run("WrongSecret", func(t *testing.T) {
+				other, _, err := manager.GenerateKey(ctx, "ci-bot", nil, time.Hour)
+				require.NoError(t, err)
+
+				// the other key's secret under this key's ID, with a valid checksum
+				parts := strings.Split(key, "_")
+				otherParts := strings.Split(other, "_")
+				body := strings.Join([]string{"tok", "live", parts[2], otherParts[3]}, "_")
+				forged := body + "_" + apiKeyChecksum(body)
+
+				_, err = manager.VerifyKey(ctx, forged)
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.Equal(t, ReasonBadSignature, VerificationReasonOf(err))
+			})
+
+			t.Run("Malformed", func(t *testing.T) {
+				typo := []byte(key)
+				typo[len("tok_live_")+3] ^= 1
+
+				for _, malformed := range []string{"", "tok_live_abc", string(typo), strings.Replace(key, "tok_live", "tok_test", 1)} {
+					_, err := manager.VerifyKey(ctx, malformed)
+					require.ErrorIs(t, err, ErrInvalidToken)
+					require.Equal(t, ReasonMalformed, VerificationReasonOf(err))
+				}
+			})
+
+			t.Run("Revoke", func(t *testing.T) {
+				revokedKey, revoked, err := manager.GenerateKey(ctx, "ci-bot", nil, time.Hour)
+				require.NoError(t, err)
+				require.NoError(t, manager.Revoke(ctx, revoked.ID))
+
+				_, err = manager.VerifyKey(ctx, revokedKey)
+				require.Equal(t, ReasonUnknownKey, VerificationReasonOf(err))
+			})
+
+			t.Run("List", func(t *testing.T) {
+				keys, err := manager.List(ctx, "ci-bot")
+				require.NoError(t, err)
+				require.NotEmpty(t, keys)
+				require.Equal(t, stored.ID, keys[0].ID)
+
+				keys, err = manager.List(ctx, "nobody")
+				require.NoError(t, err)
+				require.Empty(t, keys)
+			})
+		})
+	}
+
+	t.Run("Expired", func(t *testing.T) {
+		now := time.Now()
+		manager, err := NewAPIKeyManager("tok", NewMemoryAPIKeyStore(), WithClock(ClockFunc(func() time.Time { return now })))
+		require.NoError(t, err)
+
+		key, _, err := manager.GenerateKey(ctx, "ci-bot", nil, time.Hour)
+		require.NoError(t, err)
+
+		now = now.Add(2 * time.Hour)
+		_, err = manager.VerifyKey(ctx, key)
+		require.ErrorIs(t, err, ErrExpiredToken)
+	})
+
+	t.Run("FileStorePersists", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "keys.json")
+		store, err := NewFileAPIKeyStore(path)
+		require.NoError(t, err)
+		manager, err := NewAPIKeyManager("tok", store)
+		require.NoError(t, err)
+
+		key, _, err := manager.GenerateKey(ctx, "ci-bot", []string{"orders:read"}, time.Hour)
+		require.NoError(t, err)
+
+		// a key that was never used has no last use
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "last_used_at")
+
+		reopened, err := NewFileAPIKeyStore(path)
+		require.NoError(t, err)
+		manager, err = NewAPIKeyManager("tok", reopened)
+		require.NoError(t, err)
+
+		payload, err := manager.VerifyKey(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, "ci-bot", payload.Username)
+
+		data, err = os.ReadFile(path)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "last_used_at")
+	})
+
+	t.Run("FileStoreWriteFailure", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "keys")
+		require.NoError(t, os.Mkdir(dir, 0o700))
+		store, err := NewFileAPIKeyStore(filepath.Join(dir, "keys.json"))
+		require.NoError(t, err)
+		manager, err := NewAPIKeyManager("tok", store)
+		require.NoError(t, err)
+
+		key, stored, err := manager.GenerateKey(ctx, "ci-bot", nil, time.Hour)
+		require.NoError(t, err)
+
+		// the store's directory is gone, so nothing can be written anymore
+		require.NoError(t, os.RemoveAll(dir))
+
+		_, _, err = manager.GenerateKey(ctx, "ci-bot", nil, time.Hour)
+		require.Error(t, err)
+		keys, err := manager.List(ctx, "ci-bot")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+
+		require.Error(t, manager.Revoke(ctx, stored.ID))
+		_, err = store.Get(ctx, stored.ID)
+		require.NoError(t, err)
+
+		// the key is accepted even though its use can't be recorded
+		_, err = manager.VerifyKey(ctx, key)
+		require.NoError(t, err)
+	})
+
+	t.Run("Maker", func(t *testing.T) {
+		manager, err := NewAPIKeyManager("tok_live", NewMemoryAPIKeyStore())
+		require.NoError(t, err)
+
+		key, payload, err := manager.CreateToken("ci-bot", time.Hour)
+		require.NoError(t, err)
+
+		format, err := DetectTokenFormat(key)
+		require.NoError(t, err)
+		require.Equal(t, "apikey/tok_live", format)
+
+		handler := Middleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			verified, _ := PayloadFromContext(r.Context())
+			_, _ = w.Write([]byte(verified.ID.String()))
+		}))
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+key)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, payload.ID.String(), recorder.Body.String())
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		for _, prefix := range []string{"", "Tok", "tok-live", "_tok", "tok_"} {
+			_, err := NewAPIKeyManager(prefix, NewMemoryAPIKeyStore())
+			require.Error(t, err, prefix)
+		}
+
+		manager, err := NewAPIKeyManager("tok", NewMemoryAPIKeyStore())
+		require.NoError(t, err)
+		_, _, err = manager.GenerateKey(ctx, "ci-bot", nil, 0)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,100 @@
+package token
+
+import (
+	"aidanwoods.dev/go-paseto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testClaims struct {
+	Email    string   `json:"email"`
+	Roles    []string `json:"roles"`
+	TenantID int      `json:"tenant_id"`
+}
+
+func TestCustomClaims(t *testing.T) {
+	claims := testClaims{Email: "alice@example.com", Roles: []string{"admin", "billing"}, TenantID: 42}
+
+	for name, maker := range newTestMakers(t) {
+		t.Run(name, func(t *testing.T) {
+			claimsMaker, ok := maker.(ClaimsMaker)
+			require.True(t, ok)
+
+			token, payload, err := claimsMaker.CreateTokenWithClaims("alice", time.Minute, claims)
+			require.NoError(t, err)
+			require.NotEmpty(t, token)
+			require.Len(t, payload.Claims, 3)
+
+			verifiedPayload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+			require.Equal(t, "alice", verifiedPayload.Username)
+
+			var decoded testClaims
+			require.NoError(t, verifiedPayload.DecodeClaims(&decoded))
+			require.Equal(t, claims, decoded)
+
+			// Plain tokens carry no custom claims
+			token, _, err = maker.CreateToken("bob", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Empty(t, verifiedPayload.Claims)
+		})
+	}
+
+	t.Run("ReservedClaim", func(t *testing.T) {
+		maker, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+
+		_, _, err = maker.CreateTokenWithClaims("alice", time.Minute, map[string]string{"exp": "never"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "collides with a standard claim")
+
+		_, _, err = maker.CreateTokenWithClaims("alice", time.Minute, map[string]string{"username": "mallory"})
+		require.Error(t, err)
+	})
+
+	t.Run("NotAnObject", func(t *testing.T) {
+		_, err := NewPayloadWithClaims("alice", time.Minute, []string{"admin"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must encode to a JSON object")
+	})
+}
+
+func TestTypedMaker(t *testing.T) {
+	maker, err := NewJWTMaker(randomString(32))
+	require.NoError(t, err)
+
+	typed, err := NewTypedMaker[testClaims](maker)
+	require.NoError(t, err)
+
+	claims := testClaims{Email: "carol@example.com", Roles: []string{"viewer"}}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		token, payload, err := typed.CreateToken("carol", time.Minute, claims)
+		require.NoError(t, err)
+
+		verifiedPayload, verifiedClaims, err := typed.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verifiedPayload.ID)
+		require.Equal(t, claims, verifiedClaims)
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		token, _, err := typed.CreateToken("carol", -time.Minute, claims)
+		require.NoError(t, err)
+
+		verifiedPayload, _, err := typed.VerifyToken(token)
+		require.ErrorIs(t, err, ErrExpiredToken)
+		require.Nil(t, verifiedPayload)
+	})
+
+	t.Run("UnsupportedMaker", func(t *testing.T) {
+		_, err := NewTypedMaker[testClaims](struct{ Maker }{maker})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,16 @@
+package token
+
+import "time"
+
+// Clock tells a maker what time it is (see WithClock)
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to a Clock
+type ClockFunc func() time.Time
+
+// Now calls f
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
@@ -0,0 +1,74 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockAndLeeway(t *testing.T) {
+	start := time.Date(2020, time.January, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	clock := ClockFunc(func() time.Time { return now })
+
+	for name, maker := range newTestMakers(t, WithClock(clock), WithLeeway(5*time.Second)) {
+		t.Run(name, func(t *testing.T) {
+			now = start
+
+			token, payload, err := maker.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, start, payload.IssuedAt)
+			require.Equal(t, start.Add(time.Minute), payload.ExpiredAt)
+
+			// long expired by the system clock, but not by the maker's
+			_, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+
+			now = start.Add(time.Minute + 3*time.Second)
+			_, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+
+			now = start.Add(time.Minute + 6*time.Second)
+			verifiedPayload, err := maker.VerifyToken(token)
+			require.ErrorIs(t, err, ErrExpiredToken)
+			require.Nil(t, verifiedPayload)
+
+			t.Run("NotYetValid", func(t *testing.T) {
+				claimsMaker, ok := maker.(ClaimsMaker)
+				require.True(t, ok)
+
+				payload := &Payload{
+					ID:        payload.ID,
+					Username:  "alice",
+					IssuedAt:  start,
+					NotBefore: start.Add(time.Minute),
+					ExpiredAt: start.Add(time.Hour),
+				}
+				token, err := claimsMaker.CreateTokenFromPayload(payload)
+				require.NoError(t, err)
+
+				now = start.Add(50 * time.Second)
+				verifiedPayload, err := maker.VerifyToken(token)
+				require.ErrorIs(t, err, ErrTokenNotYetValid)
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.Nil(t, verifiedPayload)
+
+				now = start.Add(57 * time.Second)
+				_, err = maker.VerifyToken(token)
+				require.NoError(t, err)
+			})
+		})
+	}
+}
+
+func TestPayloadValidAt(t *testing.T) {
+	payload, err := NewPayload("alice", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, payload.ValidAt(payload.IssuedAt, 0))
+	require.ErrorIs(t, payload.ValidAt(payload.ExpiredAt.Add(time.Second), 0), ErrExpiredToken)
+	require.NoError(t, payload.ValidAt(payload.ExpiredAt.Add(time.Second), 2*time.Second))
+	require.ErrorIs(t, payload.ValidAt(payload.NotBefore.Add(-time.Second), 0), ErrTokenNotYetValid)
+	require.NoError(t, payload.ValidAt(payload.NotBefore.Add(-time.Second), 2*time.Second))
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// inspection is what inspect reports about a token. Nothing in it has been verified
+type inspection struct {
+	Format    string          `json:"format"`
+	Version   string          `json:"version,omitempty"`
+	Purpose   string          `json:"purpose,omitempty"`
+	Header    json.RawMessage `json:"header,omitempty"`
+	Footer    interface{}     `json:"footer,omitempty"`
+	Claims    json.RawMessage `json:"claims,omitempty"`
+	Encrypted bool            `json:"encrypted,omitempty"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+	Expired   *bool           `json:"expired,omitempty"`
+}
+
+// pasetoSignatureSizes is the signature length of public PASETO tokens, by version
+var pasetoSignatureSizes = map[string]int{"v1": 256, "v2": 64, "v3": 96, "v4": 64}
+
+// inspectToken decodes a JWT or PASETO token without verifying it
+func inspectToken(tokenString string) (*inspection, error) {
+	parts := strings.Split(tokenString, ".")
+
+	var result *inspection
+	var err error
+	switch {
+	case (len(parts) == 3 || len(parts) == 4) && pasetoSignatureSizes[parts[0]] > 0:
+		result, err = inspectPaseto(parts)
+	case len(parts) == 3:
+		result, err = inspectJWT(parts)
+	default:
+		return nil, fmt.Errorf("token is neither a JWT nor a PASETO token")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.setExpiry()
+	return result, nil
+}
+
+func inspectJWT(parts []string) (*inspection, error) {
+	header, err := decodeJSONSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+	claims, err := decodeJSONSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	return &inspection{Format: "jwt", Header: header, Claims: claims}, nil
+}
+
+func inspectPaseto(parts []string) (*inspection, error) {
+	result := &inspection{Format: "paseto", Version: parts[0], Purpose: parts[1]}
+
+	if len(parts) == 4 {
+		footer, err := base64.RawURLEncoding.DecodeString(parts[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid PASETO footer: %w", err)
+		}
+		if json.Valid(footer) {
+			result.Footer = json.RawMessage(footer)
+		} else {
+			result.Footer = string(footer)
+		}
+	}
+
+	switch parts[1] {
+	case "local":
+		result.Encrypted = true
+	case "public":
+		body, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid PASETO payload: %w", err)
+		}
+		signatureSize := pasetoSignatureSizes[parts[0]]
+		if len(body) < signatureSize || !json.Valid(body[:len(body)-signatureSize]) {
+			return nil, fmt.Errorf("invalid PASETO payload")
+		}
+		result.Claims = body[:len(body)-signatureSize]
+	default:
+		return nil, fmt.Errorf("unknown PASETO purpose %q", parts[1])
+	}
+
+	return result, nil
+}
+
+func decodeJSONSegment(segment string) (json.RawMessage, error) {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("segment is not JSON")
+	}
+	return data, nil
+}
+
+// setExpiry flags the token as expired from its "exp" claim, a NumericDate in JWTs and an RFC 3339 time in
+// PASETO tokens, or from the legacy "expired_at" claim
+func (result *inspection) setExpiry() {
+	if result.Claims == nil {
+		return
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(result.Claims, &claims); err != nil {
+		return
+	}
+
+	var expiresAt time.Time
+	switch exp := claims["exp"].(type) {
+	case float64:
+		expiresAt = time.Unix(int64(exp), 0)
+	case string:
+		expiresAt, _ = time.Parse(time.RFC3339, exp)
+	default:
+		if legacy, ok := claims["expired_at"].(string); ok {
+			expiresAt, _ = time.Parse(time.RFC3339Nano, legacy)
+		}
+	}
+	if expiresAt.IsZero() {
+		return
+	}
+
+	expired := time.Now().After(expiresAt)
+	result.ExpiresAt = &expiresAt
+	result.Expired = &expired
+}
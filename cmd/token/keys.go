@@ -0,0 +1,345 @@
+package main
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/fsobh/token"
+	"golang.org/x/crypto/ed25519"
+)
+
+// makerTypes lists the maker types the CLI can generate keys and tokens for
+var makerTypes = []string{"jwt", "jwt-asym", "v2-local", "v2-public", "v3-local", "v3-public", "v4-local", "v4-public"}
+
+func isMakerType(makerType string) bool {
+	for _, t := range makerTypes {
+		if t == makerType {
+			return true
+		}
+	}
+	return false
+}
+
+func isAsymmetric(makerType string) bool {
+	return makerType == "jwt-asym" || strings.HasSuffix(makerType, "-public")
+}
+
+func isEd25519(makerType string) bool {
+	return makerType == "jwt-asym" || makerType == "v2-public" || makerType == "v4-public"
+}
+
+// generateKey returns a new raw secret key and, for asymmetric types, its public key
+func generateKey(makerType string) (secret, public []byte, err error) {
+	switch {
+	case makerType == "jwt":
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		// JWT secrets are used as text
+		return []byte(hex.EncodeToString(buf)), nil, nil
+	case isEd25519(makerType):
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		return privateKey, publicKey, nil
+	case makerType == "v3-public":
+		key := paseto.NewV3AsymmetricSecretKey()
+		return key.ExportBytes(), key.Public().ExportBytes(), nil
+	default:
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		return buf, nil, nil
+	}
+}
+
+// derivePublicKey returns the public key of a raw secret key
+func derivePublicKey(makerType string, secret []byte) ([]byte, error) {
+	switch {
+	case isEd25519(makerType):
+		if len(secret) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("Ed25519 private key must be %d bytes long", ed25519.PrivateKeySize)
+		}
+		return ed25519.PrivateKey(secret).Public().(ed25519.PublicKey), nil
+	case makerType == "v3-public":
+		key, err := paseto.NewV3AsymmetricSecretKeyFromBytes(secret)
+		if err != nil {
+			return nil, err
+		}
+		return key.Public().ExportBytes(), nil
+	default:
+		return nil, fmt.Errorf("%s keys have no public part", makerType)
+	}
+}
+
+// inputKey is a key given to the CLI, either raw or as a PASERK left for the token package to decode
+type inputKey struct {
+	raw    []byte
+	paserk string
+}
+
+func (k inputKey) empty() bool {
+	return k.raw == nil && k.paserk == ""
+}
+
+// encodeKey formats a raw key as hex, PEM or PASERK
+func encodeKey(makerType, format string, key []byte, public bool) (string, error) {
+	switch format {
+	case "hex":
+		if makerType == "jwt" {
+			return string(key), nil
+		}
+		return hex.EncodeToString(key), nil
+	case "pem":
+		return encodePEM(makerType, key, public)
+	case "paserk":
+		return encodePASERK(makerType, key, public)
+	default:
+		return "", fmt.Errorf("unknown key format %q", format)
+	}
+}
+
+// encodePASERK exports a raw PASETO key as a PASERK through the maker it belongs to
+func encodePASERK(makerType string, key []byte, public bool) (string, error) {
+	if !strings.HasPrefix(makerType, "v") {
+		return "", fmt.Errorf("PASERK is only defined for PASETO keys")
+	}
+
+	var (
+		maker token.Maker
+		err   error
+	)
+	if public {
+		maker, err = newVerifier(makerType, key)
+	} else {
+		maker, err = newRawMaker(makerType, key, nil)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	switch maker := maker.(type) {
+	case interface{ PASERK() string }:
+		return maker.PASERK(), nil
+	case interface {
+		SecretPASERK() string
+		PublicPASERK() string
+	}:
+		if public {
+			return maker.PublicPASERK(), nil
+		}
+		return maker.SecretPASERK(), nil
+	default:
+		return "", fmt.Errorf("%s keys can't be exported as PASERK", makerType)
+	}
+}
+
+func encodePEM(makerType string, key []byte, public bool) (string, error) {
+	var (
+		der []byte
+		err error
+	)
+	switch {
+	case isEd25519(makerType) && public:
+		der, err = x509.MarshalPKIXPublicKey(ed25519.PublicKey(key))
+	case isEd25519(makerType):
+		der, err = x509.MarshalPKCS8PrivateKey(ed25519.PrivateKey(key))
+	case makerType == "v3-public" && public:
+		x, y := elliptic.UnmarshalCompressed(elliptic.P384(), key)
+		if x == nil {
+			return "", fmt.Errorf("invalid P-384 public key")
+		}
+		der, err = x509.MarshalPKIXPublicKey(&ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y})
+	case makerType == "v3-public":
+		var privateKey *ecdh.PrivateKey
+		if privateKey, err = ecdh.P384().NewPrivateKey(key); err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(privateKey)
+		}
+	default:
+		return "", fmt.Errorf("PEM is only supported for asymmetric keys")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	blockType := "PRIVATE KEY"
+	if public {
+		blockType = "PUBLIC KEY"
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})), nil
+}
+
+// decodeKey parses a key given as hex, PEM or PASERK. PASERKs are kept as they are, for newMaker to decode
+func decodeKey(makerType, text string, public bool) (inputKey, error) {
+	text = strings.TrimSpace(text)
+	if makerType == "jwt" {
+		return inputKey{raw: []byte(text)}, nil
+	}
+	if strings.HasPrefix(text, "-----BEGIN") {
+		raw, err := decodePEM(makerType, text, public)
+		return inputKey{raw: raw}, err
+	}
+	if _, err := token.PASERKKeyID(text); err == nil {
+		return inputKey{paserk: text}, nil
+	}
+
+	raw, err := hex.DecodeString(text)
+	if err != nil {
+		return inputKey{}, fmt.Errorf("key is neither hex, PEM nor PASERK")
+	}
+	return inputKey{raw: raw}, nil
+}
+
+func decodePEM(makerType, text string, public bool) ([]byte, error) {
+	var (
+		key interface{}
+		err error
+	)
+	if public {
+		key, err = token.ParsePEMPublicKey([]byte(text))
+	} else {
+		key, err = token.ParsePEMPrivateKey([]byte(text))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		if isEd25519(makerType) {
+			return key, nil
+		}
+	case ed25519.PublicKey:
+		if isEd25519(makerType) {
+			return key, nil
+		}
+	case *ecdsa.PrivateKey:
+		if makerType == "v3-public" {
+			secretKey, err := paseto.NewV3AsymmetricSecretKeyFromEcdsa(*key)
+			if err != nil {
+				return nil, err
+			}
+			return secretKey.ExportBytes(), nil
+		}
+	case *ecdsa.PublicKey:
+		if makerType == "v3-public" {
+			publicKey, err := paseto.NewV3AsymmetricPublicKeyFromEcdsa(*key)
+			if err != nil {
+				return nil, err
+			}
+			return publicKey.ExportBytes(), nil
+		}
+	}
+	return nil, fmt.Errorf("PEM key of type %T can't be used with %s", key, makerType)
+}
+
+// newMaker builds the maker of the given type. When only a public key is given the maker is a verifier
+func newMaker(makerType string, secret, public inputKey, opts ...token.Option) (token.Maker, error) {
+	if secret.paserk == "" && public.paserk == "" {
+		return newRawMaker(makerType, secret.raw, public.raw, opts...)
+	}
+	if secret.raw != nil || public.raw != nil {
+		return nil, fmt.Errorf("PASERK keys can't be mixed with keys in other formats")
+	}
+	return newPASERKMaker(makerType, secret.paserk, public.paserk, opts...)
+}
+
+// newRawMaker builds the maker of the given type from raw keys
+func newRawMaker(makerType string, secret, public []byte, opts ...token.Option) (token.Maker, error) {
+	if isAsymmetric(makerType) {
+		if secret == nil && public == nil {
+			return nil, fmt.Errorf("a private or public key is required")
+		}
+		if secret == nil {
+			return newVerifier(makerType, public, opts...)
+		}
+		if public == nil {
+			derived, err := derivePublicKey(makerType, secret)
+			if err != nil {
+				return nil, err
+			}
+			public = derived
+		}
+	} else if secret == nil {
+		return nil, fmt.Errorf("a secret key is required")
+	}
+
+	switch makerType {
+	case "jwt":
+		return token.NewJWTMaker(string(secret), opts...)
+	case "jwt-asym":
+		return token.NewAsymJWTMaker(ed25519.PrivateKey(secret), ed25519.PublicKey(public), opts...)
+	case "v2-local":
+		return token.NewPasetoV2Local(hex.EncodeToString(secret), opts...)
+	case "v2-public":
+		return token.NewPasetoV2Public(hex.EncodeToString(secret), hex.EncodeToString(public), opts...)
+	case "v3-local":
+		return token.NewPasetoV3Local(hex.EncodeToString(secret), opts...)
+	case "v3-public":
+		return token.NewPasetoV3Public(hex.EncodeToString(secret), hex.EncodeToString(public), opts...)
+	case "v4-local":
+		return token.NewPasetoV4Local(hex.EncodeToString(secret), opts...)
+	case "v4-public":
+		return token.NewPasetoV4Public(hex.EncodeToString(secret), hex.EncodeToString(public), opts...)
+	default:
+		return nil, fmt.Errorf("unknown maker type %q", makerType)
+	}
+}
+
+// newPASERKMaker builds the maker of the given type from PASERKs, which must be of the type's version and purpose
+func newPASERKMaker(makerType, secret, public string, opts ...token.Option) (token.Maker, error) {
+	if isAsymmetric(makerType) && secret == "" {
+		switch makerType {
+		case "v2-public":
+			return token.NewPasetoV2PublicVerifierFromPASERK(public, opts...)
+		case "v3-public":
+			return token.NewPasetoV3PublicVerifierFromPASERK(public, opts...)
+		case "v4-public":
+			return token.NewPasetoV4PublicVerifierFromPASERK(public, opts...)
+		}
+	}
+
+	switch makerType {
+	case "v2-local":
+		return token.NewPasetoV2LocalFromPASERK(secret, opts...)
+	case "v2-public":
+		return token.NewPasetoV2PublicFromPASERK(secret, public, opts...)
+	case "v3-local":
+		return token.NewPasetoV3LocalFromPASERK(secret, opts...)
+	case "v3-public":
+		return token.NewPasetoV3PublicFromPASERK(secret, public, opts...)
+	case "v4-local":
+		return token.NewPasetoV4LocalFromPASERK(secret, opts...)
+	case "v4-public":
+		return token.NewPasetoV4PublicFromPASERK(secret, public, opts...)
+	default:
+		return nil, fmt.Errorf("PASERK is only defined for PASETO keys")
+	}
+}
+
+// newVerifier builds a verifier of the given asymmetric type from its public key
+func newVerifier(makerType string, public []byte, opts ...token.Option) (token.Maker, error) {
+	switch makerType {
+	case "jwt-asym":
+		return token.NewAsymJWTVerifier(ed25519.PublicKey(public), opts...)
+	case "v2-public":
+		return token.NewPasetoV2PublicVerifier(hex.EncodeToString(public), opts...)
+	case "v3-public":
+		return token.NewPasetoV3PublicVerifier(hex.EncodeToString(public), opts...)
+	case "v4-public":
+		return token.NewPasetoV4PublicVerifier(hex.EncodeToString(public), opts...)
+	default:
+		return nil, fmt.Errorf("unknown maker type %q", makerType)
+	}
+}
@@ -0,0 +1,279 @@
+// Command token generates keys and mints, inspects and verifies tokens. Every subcommand prints JSON to stdout.
+//
+//	token keygen  -type v4-public [-format hex|pem|paserk]
+//	token mint    -type v4-public -key KEY -username alice [-duration 15m] [-claims '{"role":"admin"}']
+//	token inspect TOKEN
+//	token verify  -type v4-public -public-key KEY TOKEN
+//
+// Keys are given as hex, PEM or PASERK, or read from a file with -key @path. TOKEN is read from stdin
+// when omitted or "-".
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fsobh/token"
+)
+
+// errVerificationFailed makes the command exit with a failure once the failed verification has been reported
+var errVerificationFailed = errors.New("verification failed")
+
+var usage = `usage: token <command> [flags]
+
+commands:
+  keygen   generate a key for a maker type
+  mint     create a token
+  inspect  decode a token without verifying it
+  verify   verify a token
+
+maker types: ` + strings.Join(makerTypes, ", ") + `
+
+Run "token <command> -h" for the flags of a command.
+`
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+	case errors.Is(err, errVerificationFailed):
+		os.Exit(1)
+	default:
+		fmt.Fprintln(os.Stderr, "token:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("missing command")
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "keygen":
+		return keygen(args, stdout, stderr)
+	case "mint":
+		return mint(args, stdout, stderr)
+	case "inspect":
+		return inspect(args, stdin, stdout, stderr)
+	case "verify":
+		return verify(args, stdin, stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func keygen(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	makerType := flags.String("type", "", "maker type")
+	format := flags.String("format", "hex", "key format: hex, pem or paserk")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !isMakerType(*makerType) {
+		return fmt.Errorf("unknown maker type %q", *makerType)
+	}
+
+	secret, public, err := generateKey(*makerType)
+	if err != nil {
+		return fmt.Errorf("could not generate key: %w", err)
+	}
+
+	output := map[string]string{"type": *makerType, "format": *format}
+	if !isAsymmetric(*makerType) {
+		if output["key"], err = encodeKey(*makerType, *format, secret, false); err != nil {
+			return err
+		}
+		return writeJSON(stdout, output)
+	}
+
+	if output["private_key"], err = encodeKey(*makerType, *format, secret, false); err != nil {
+		return err
+	}
+	if output["public_key"], err = encodeKey(*makerType, *format, public, true); err != nil {
+		return err
+	}
+	return writeJSON(stdout, output)
+}
+
+func mint(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("mint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	makerType := flags.String("type", "", "maker type")
+	key := flags.String("key", "", "secret or private key, or @file")
+	username := flags.String("username", "", "username the token is issued for")
+	duration := flags.Duration("duration", 15*time.Minute, "token lifetime")
+	claims := flags.String("claims", "", "custom claims, as a JSON object")
+	subject := flags.String("sub", "", "subject claim")
+	issuer := flags.String("iss", "", "issuer claim")
+	audience := flags.String("aud", "", "comma separated audience claim")
+	scope := flags.String("scope", "", "space separated scope claim")
+	roles := flags.String("roles", "", "comma separated roles claim")
+	keyID := flags.String("kid", "", "key ID written to the token")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" {
+		return fmt.Errorf("-username is required")
+	}
+
+	secret, err := readKey(*makerType, *key, false)
+	if err != nil {
+		return err
+	}
+	if secret.empty() {
+		return fmt.Errorf("-key is required")
+	}
+
+	opts := []token.Option{token.WithKeyID(*keyID), token.WithIssuer(*issuer)}
+	if *audience != "" {
+		opts = append(opts, token.WithAudience(strings.Split(*audience, ",")...))
+	}
+	maker, err := newMaker(*makerType, secret, inputKey{}, opts...)
+	if err != nil {
+		return err
+	}
+
+	var customClaims interface{}
+	if *claims != "" {
+		customClaims = json.RawMessage(*claims)
+	}
+	payload, err := token.NewPayloadWithClaims(*username, *duration, customClaims)
+	if err != nil {
+		return err
+	}
+	payload.Subject = *subject
+	payload.Scopes = strings.Fields(*scope)
+	if *roles != "" {
+		payload.Roles = strings.Split(*roles, ",")
+	}
+
+	tokenString, err := maker.(token.ClaimsMaker).CreateTokenFromPayload(payload)
+	if err != nil {
+		return fmt.Errorf("could not create token: %w", err)
+	}
+
+	return writeJSON(stdout, map[string]interface{}{"token": tokenString, "payload": payload})
+}
+
+func inspect(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tokenString, err := readToken(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	result, err := inspectToken(tokenString)
+	if err != nil {
+		return err
+	}
+	return writeJSON(stdout, result)
+}
+
+func verify(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	makerType := flags.String("type", "", "maker type")
+	key := flags.String("key", "", "secret or private key, or @file")
+	publicKey := flags.String("public-key", "", "public key of asymmetric maker types, or @file")
+	issuer := flags.String("iss", "", "expected issuer")
+	audience := flags.String("aud", "", "expected audience")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	tokenString, err := readToken(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	secret, err := readKey(*makerType, *key, false)
+	if err != nil {
+		return err
+	}
+	public, err := readKey(*makerType, *publicKey, true)
+	if err != nil {
+		return err
+	}
+
+	maker, err := newMaker(*makerType, secret, public, token.WithExpectedIssuer(*issuer), token.WithExpectedAudience(*audience))
+	if err != nil {
+		return err
+	}
+
+	payload, err := maker.VerifyToken(tokenString)
+	if err != nil {
+		result := map[string]interface{}{"valid": false, "error": err.Error()}
+		if reason := token.VerificationReasonOf(err); reason != 0 {
+			result["reason"] = reason.String()
+		}
+		if err := writeJSON(stdout, result); err != nil {
+			return err
+		}
+		return errVerificationFailed
+	}
+	return writeJSON(stdout, map[string]interface{}{"valid": true, "payload": payload})
+}
+
+// readKey decodes a key flag, reading it from a file when it starts with @. An empty flag yields an empty key
+func readKey(makerType, value string, public bool) (inputKey, error) {
+	if !isMakerType(makerType) {
+		return inputKey{}, fmt.Errorf("unknown maker type %q", makerType)
+	}
+	if value == "" {
+		return inputKey{}, nil
+	}
+
+	if strings.HasPrefix(value, "@") {
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return inputKey{}, fmt.Errorf("could not read key: %w", err)
+		}
+		value = string(data)
+	}
+	return decodeKey(makerType, value, public)
+}
+
+// readToken returns the token argument, or reads the token from stdin
+func readToken(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 1 {
+		return "", fmt.Errorf("expected a single token")
+	}
+	if len(args) == 1 && args[0] != "-" {
+		return args[0], nil
+	}
+
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("could not read token: %w", err)
+	}
+	tokenString := strings.TrimSpace(string(data))
+	if tokenString == "" {
+		return "", fmt.Errorf("missing token")
+	}
+	return tokenString, nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
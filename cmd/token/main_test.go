@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func runJSON(t *testing.T, stdin string, args ...string) (map[string]interface{}, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+
+	var output map[string]interface{}
+	if stdout.Len() > 0 {
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &output))
+	}
+	return output, err
+}
+
+func TestCLI(t *testing.T) {
+	for _, makerType := range makerTypes {
+		formats := []string{"hex"}
+		if isAsymmetric(makerType) {
+			formats = append(formats, "pem")
+		}
+		if strings.HasPrefix(makerType, "v") {
+			formats = append(formats, "paserk")
+		}
+
+		for _, format := range formats {
+			t.Run(makerType+"/"+format, func(t *testing.T) {
+				keys, err := runJSON(t, "", "keygen", "-type", makerType, "-format", format)
+				require.NoError(t, err)
+
+				signingKey, verificationKey := "-key", "-key"
+				secret, _ := keys["key"].(string)
+				public := secret
+				if isAsymmetric(makerType) {
+					secret, public = keys["private_key"].(string), keys["public_key"].(string)
+					verificationKey = "-public-key"
+				}
+
+				minted, err := runJSON(t, "", "mint", "-type", makerType, signingKey, secret,
+					"-username", "alice", "-claims", `{"role":"admin"}`, "-iss", "cli", "-kid", "k1")
+				require.NoError(t, err)
+				tokenString := minted["token"].(string)
+
+				verified, err := runJSON(t, "", "verify", "-type", makerType, verificationKey, public, "-iss", "cli", tokenString)
+				require.NoError(t, err)
+				require.Equal(t, true, verified["valid"])
+				payload := verified["payload"].(map[string]interface{})
+				require.Equal(t, "alice", payload["username"])
+				require.Equal(t, "admin", payload["role"])
+
+				inspected, err := runJSON(t, tokenString, "inspect")
+				require.NoError(t, err)
+				if strings.HasSuffix(makerType, "-local") {
+					require.Equal(t, true, inspected["encrypted"])
+				} else {
+					require.Equal(t, "alice", inspected["claims"].(map[string]interface{})["username"])
+					require.Equal(t, false, inspected["expired"])
+				}
+			})
+		}
+	}
+
+	t.Run("VerificationFailure", func(t *testing.T) {
+		keys, err := runJSON(t, "", "keygen", "-type", "v4-local")
+		require.NoError(t, err)
+		otherKeys, err := runJSON(t, "", "keygen", "-type", "v4-local")
+		require.NoError(t, err)
+
+		minted, err := runJSON(t, "", "mint", "-type", "v4-local", "-key", keys["key"].(string), "-username", "alice")
+		require.NoError(t, err)
+
+		verified, err := runJSON(t, "", "verify", "-type", "v4-local", "-key", otherKeys["key"].(string), minted["token"].(string))
+		require.ErrorIs(t, err, errVerificationFailed)
+		require.Equal(t, false, verified["valid"])
+		require.NotEmpty(t, verified["error"])
+		require.Equal(t, "bad_signature", verified["reason"])
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		keys, err := runJSON(t, "", "keygen", "-type", "jwt")
+		require.NoError(t, err)
+
+		minted, err := runJSON(t, "", "mint", "-type", "jwt", "-key", keys["key"].(string), "-username", "alice", "-duration", "-1m")
+		require.NoError(t, err)
+
+		inspected, err := runJSON(t, "", "inspect", minted["token"].(string))
+		require.NoError(t, err)
+		require.Equal(t, "jwt", inspected["format"])
+		require.Equal(t, "HS256", inspected["header"].(map[string]interface{})["alg"])
+		require.Equal(t, true, inspected["expired"])
+	})
+
+	t.Run("ScopesAndRoles", func(t *testing.T) {
+		keys, err := runJSON(t, "", "keygen", "-type", "jwt")
+		require.NoError(t, err)
+
+		minted, err := runJSON(t, "", "mint", "-type", "jwt", "-key", keys["key"].(string), "-username", "alice",
+			"-scope", "orders:read orders:write", "-roles", "admin,support")
+		require.NoError(t, err)
+
+		inspected, err := runJSON(t, "", "inspect", minted["token"].(string))
+		require.NoError(t, err)
+		claims := inspected["claims"].(map[string]interface{})
+		require.Equal(t, "orders:read orders:write", claims["scope"])
+		require.Equal(t, []interface{}{"admin", "support"}, claims["roles"])
+	})
+
+	t.Run("KeyFile", func(t *testing.T) {
+		keys, err := runJSON(t, "", "keygen", "-type", "v4-public", "-format", "pem")
+		require.NoError(t, err)
+
+		path := filepath.Join(t.TempDir(), "private.pem")
+		require.NoError(t, os.WriteFile(path, []byte(keys["private_key"].(string)), 0o600))
+
+		minted, err := runJSON(t, "", "mint", "-type", "v4-public", "-key", "@"+path, "-username", "alice", "-kid", "k1")
+		require.NoError(t, err)
+
+		inspected, err := runJSON(t, "", "inspect", minted["token"].(string))
+		require.NoError(t, err)
+		require.Equal(t, map[string]interface{}{"kid": "k1"}, inspected["footer"])
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := runJSON(t, "")
+		require.Error(t, err)
+		_, err = runJSON(t, "", "frobnicate")
+		require.Error(t, err)
+		_, err = runJSON(t, "", "keygen", "-type", "v5-local")
+		require.Error(t, err)
+		_, err = runJSON(t, "", "keygen", "-type", "jwt", "-format", "paserk")
+		require.Error(t, err)
+		_, err = runJSON(t, "", "inspect", "not-a-token")
+		require.Error(t, err)
+
+		// A PASERK of another version or purpose is rejected
+		keys, err := runJSON(t, "", "keygen", "-type", "v4-local", "-format", "paserk")
+		require.NoError(t, err)
+		_, err = runJSON(t, "", "mint", "-type", "v2-local", "-key", keys["key"].(string), "-username", "alice")
+		require.Error(t, err)
+
+		// and so is one of the wrong length
+		_, err = runJSON(t, "", "mint", "-type", "v4-local", "-key", "k4.local.AAAA", "-username", "alice")
+		require.Error(t, err)
+
+		// PASERKs can't be paired with keys in other formats
+		paserkKeys, err := runJSON(t, "", "keygen", "-type", "v4-public", "-format", "paserk")
+		require.NoError(t, err)
+		hexKeys, err := runJSON(t, "", "keygen", "-type", "v4-public")
+		require.NoError(t, err)
+		_, err = runJSON(t, "", "verify", "-type", "v4-public", "-key", paserkKeys["private_key"].(string),
+			"-public-key", hexKeys["public_key"].(string), "not-a-token")
+		require.ErrorContains(t, err, "can't be mixed")
+	})
+}
@@ -0,0 +1,17 @@
+package token
+
+import "context"
+
+// payloadContextKey is the context key under which a verified Payload is stored
+type payloadContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the verified payload
+func NewContext(ctx context.Context, payload *Payload) context.Context {
+	return context.WithValue(ctx, payloadContextKey{}, payload)
+}
+
+// PayloadFromContext returns the verified payload stored in ctx by the authentication middleware, if any
+func PayloadFromContext(ctx context.Context) (*Payload, bool) {
+	payload, ok := ctx.Value(payloadContextKey{}).(*Payload)
+	return payload, ok && payload != nil
+}
@@ -0,0 +1,182 @@
+package token
+
+import (
+	"context"
+	"time"
+)
+
+// AsContextMaker returns maker as a ContextMaker. A maker that doesn't implement ContextMaker is wrapped so the
+// context is checked before every call, the call itself can't be cancelled
+func AsContextMaker(maker Maker) ContextMaker {
+	if contextMaker, ok := maker.(ContextMaker); ok {
+		return contextMaker
+	}
+	return contextAdapter{Maker: maker}
+}
+
+// contextAdapter adds the ContextMaker methods to a Maker that lacks them
+type contextAdapter struct {
+	Maker
+}
+
+func (adapter contextAdapter) makerOptions() options {
+	return optionsOf(adapter.Maker)
+}
+
+func (adapter contextAdapter) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createTokenContext(ctx, adapter.Maker, username, duration)
+}
+
+func (adapter contextAdapter) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, adapter.Maker, token)
+}
+
+// createTokenContext creates a token with a maker that doesn't call out to anything, once ctx is known to be live
+func createTokenContext(ctx context.Context, maker Maker, username string, duration time.Duration) (string, *Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	return maker.CreateToken(username, duration)
+}
+
+// createPayloadTokenContext creates a token for a new payload with create, which passes ctx on to a KeySigner
+func createPayloadTokenContext(ctx context.Context, o options, username string, duration time.Duration, create func(context.Context, *Payload) (string, error)) (string, *Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+
+	payload, err := newPayloadAt(username, duration, nil, o.now())
+	if err != nil {
+		return "", nil, err
+	}
+	token, err := create(ctx, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+// payloadContextMaker is implemented by the makers that pass a context on to a KeySigner when creating a token
+// for a payload
+type payloadContextMaker interface {
+	createTokenFromPayload(ctx context.Context, payload *Payload) (string, error)
+}
+
+// createTokenFromPayloadContext creates a token for payload with maker, passing ctx on when maker can take it
+func createTokenFromPayloadContext(ctx context.Context, maker ClaimsMaker, payload *Payload) (string, error) {
+	if maker, ok := maker.(payloadContextMaker); ok {
+		return maker.createTokenFromPayload(ctx, payload)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return maker.CreateTokenFromPayload(payload)
+}
+
+// verifyTokenContext verifies a token with a maker that doesn't call out to anything, once ctx is known to be live
+func verifyTokenContext(ctx context.Context, maker Maker, token string) (*Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return maker.VerifyToken(token)
+}
+
+// CreateTokenContext creates a token, the context is only checked for cancellation
+func (maker *JWTMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createTokenContext(ctx, maker, username, duration)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *JWTMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the maker's KeySigner
+func (maker *AsymJWTMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *AsymJWTMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the maker's KeySigner
+func (maker *RSAJWTMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *RSAJWTMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the maker's KeySigner
+func (maker *ECDSAJWTMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *ECDSAJWTMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, the context is only checked for cancellation
+func (maker *PasetoV2Local) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createTokenContext(ctx, maker, username, duration)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *PasetoV2Local) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the maker's KeySigner
+func (maker *PasetoV2Public) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *PasetoV2Public) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, the context is only checked for cancellation
+func (maker *PasetoV3Local) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createTokenContext(ctx, maker, username, duration)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *PasetoV3Local) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the maker's KeySigner
+func (maker *PasetoV3Public) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *PasetoV3Public) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, the context is only checked for cancellation
+func (maker *PasetoV4Local) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createTokenContext(ctx, maker, username, duration)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *PasetoV4Local) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the maker's KeySigner
+func (maker *PasetoV4Public) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, the context is only checked for cancellation
+func (maker *PasetoV4Public) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return verifyTokenContext(ctx, maker, token)
+}
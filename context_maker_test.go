@@ -0,0 +1,104 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type contextKey struct{}
+
+// contextRecordingStore records the context it is asked to look a token up with
+type contextRecordingStore struct {
+	RevocationStore
+	ctx context.Context
+}
+
+func (store *contextRecordingStore) IsRevoked(ctx context.Context, id uuid.UUID) (bool, error) {
+	store.ctx = ctx
+	return store.RevocationStore.IsRevoked(ctx, id)
+}
+
+func TestContextMaker(t *testing.T) {
+	makers := newTestMakers(t)
+
+	keyedMaker, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex(), WithKeyID("k1"))
+	require.NoError(t, err)
+	makers["Keyring"], err = NewKeyring(keyedMaker)
+	require.NoError(t, err)
+	makers["MultiMaker"], err = NewMultiMaker(makers["PasetoV4Public"], makers["JWTMaker"])
+	require.NoError(t, err)
+	makers["RevocableMaker"] = NewRevocableMaker(makers["PasetoV2Local"], NewMemoryRevocationStore())
+	makers["APIKeyManager"], err = NewAPIKeyManager("tok", NewMemoryAPIKeyStore())
+	require.NoError(t, err)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, maker := range makers {
+		t.Run(name, func(t *testing.T) {
+			contextMaker, ok := maker.(ContextMaker)
+			require.True(t, ok)
+			require.Equal(t, contextMaker, AsContextMaker(maker))
+
+			token, payload, err := contextMaker.CreateTokenContext(context.Background(), "alice", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := contextMaker.VerifyTokenContext(context.Background(), token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+
+			_, _, err = contextMaker.CreateTokenContext(canceled, "alice", time.Minute)
+			require.ErrorIs(t, err, context.Canceled)
+			_, err = contextMaker.VerifyTokenContext(canceled, token)
+			require.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
+
+func TestAsContextMaker(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(randomString(32))
+	require.NoError(t, err)
+	counting := &countingMaker{Maker: jwtMaker}
+
+	adapted := AsContextMaker(counting)
+	require.NotEqual(t, Maker(counting), Maker(adapted))
+
+	token, payload, err := adapted.CreateTokenContext(context.Background(), "alice", time.Minute)
+	require.NoError(t, err)
+	verifiedPayload, err := adapted.VerifyTokenContext(context.Background(), token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verifiedPayload.ID)
+	require.Equal(t, 1, counting.verified)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = adapted.VerifyTokenContext(canceled, token)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, counting.verified)
+}
+
+func TestContextReachesStores(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(randomString(32))
+	require.NoError(t, err)
+	v2Local, err := NewPasetoV2Local(paseto.NewV2SymmetricKey().ExportHex())
+	require.NoError(t, err)
+
+	store := &contextRecordingStore{RevocationStore: NewMemoryRevocationStore()}
+	multi, err := NewMultiMaker(v2Local)
+	require.NoError(t, err)
+	require.NoError(t, multi.RegisterFormat("jwt/HS256", NewRevocableMaker(jwtMaker, store)))
+
+	token, _, err := jwtMaker.CreateToken("alice", time.Minute)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), contextKey{}, "request-1")
+	_, err = multi.VerifyTokenContext(ctx, token)
+	require.NoError(t, err)
+	require.NotNil(t, store.ctx)
+	require.Equal(t, "request-1", store.ctx.Value(contextKey{}))
+}
@@ -0,0 +1,59 @@
+package grpcauth
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// TokenSource supplies the token a client attaches to its calls
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc adapts a function to a TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource returns a TokenSource that always supplies the same token
+func StaticTokenSource(token string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (string, error) {
+		return token, nil
+	})
+}
+
+// UnaryClientInterceptor attaches a bearer token from source to the "authorization" metadata of every unary call
+func UnaryClientInterceptor(source TokenSource) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, err := withToken(ctx, source)
+		if err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is the streaming counterpart of UnaryClientInterceptor
+func StreamClientInterceptor(source TokenSource) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, err := withToken(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func withToken(ctx context.Context, source TokenSource) (context.Context, error) {
+	token, err := source.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not get token: %w", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token), nil
+}
@@ -0,0 +1,141 @@
+// Package grpcauth authenticates gRPC calls with the tokens of a token.Maker
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/fsobh/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationKey is the metadata key carrying the token, gRPC metadata keys are lowercase
+const authorizationKey = "authorization"
+
+// Option configures the server interceptors
+type Option func(*interceptor)
+
+type interceptor struct {
+	maker    token.ContextMaker
+	optional map[string]bool
+}
+
+// WithOptionalMethods lets calls to the given full method names (e.g. "/grpc.health.v1.Health/Check")
+// through without a token. Tokens they do carry are still verified, and rejected if invalid
+func WithOptionalMethods(fullMethods ...string) Option {
+	return func(i *interceptor) {
+		for _, method := range fullMethods {
+			i.optional[method] = true
+		}
+	}
+}
+
+func newInterceptor(maker token.Maker, opts []Option) *interceptor {
+	i := &interceptor{
+		maker:    token.AsContextMaker(maker),
+		optional: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// UnaryServerInterceptor verifies the token in the "authorization" metadata of every unary call and stores
+// its payload in the handler's context, see token.PayloadFromContext. Calls without a valid token fail
+// with codes.Unauthenticated, those whose token couldn't be verified with codes.Unavailable
+func UnaryServerInterceptor(maker token.Maker, opts ...Option) grpc.UnaryServerInterceptor {
+	i := newInterceptor(maker, opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor
+func StreamServerInterceptor(maker token.Maker, opts ...Option) grpc.StreamServerInterceptor {
+	i := newInterceptor(maker, opts)
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate verifies the call's token and returns the context carrying its payload
+func (i *interceptor) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	tokenString := tokenFromMetadata(ctx)
+	if tokenString == "" {
+		if i.optional[fullMethod] {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, token.ErrMissingToken.Error())
+	}
+
+	payload, err := i.maker.VerifyTokenContext(ctx, tokenString)
+	if err != nil {
+		return nil, verificationStatus(err)
+	}
+
+	return token.NewContext(ctx, payload), nil
+}
+
+// verificationStatus converts a verification error: rejected tokens fail with codes.Unauthenticated, tokens that
+// couldn't be verified, e.g. because a revocation store is down, with codes.Unavailable
+func verificationStatus(err error) error {
+	switch {
+	case token.IsTokenRejected(err):
+		return status.Error(codes.Unauthenticated, unauthenticatedMessage(err))
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Unavailable, "token could not be verified")
+	}
+}
+
+// unauthenticatedMessage says why a token was rejected, without revealing anything about the maker's keys
+func unauthenticatedMessage(err error) string {
+	switch {
+	case errors.Is(err, token.ErrExpiredToken):
+		return "token has expired"
+	case errors.Is(err, token.ErrTokenNotYetValid):
+		return "token is not valid yet"
+	case errors.Is(err, token.ErrRevokedToken):
+		return "token has been revoked"
+	default:
+		return token.ErrInvalidToken.Error()
+	}
+}
+
+// tokenFromMetadata reads the token from the incoming "authorization" metadata, with or without a Bearer scheme
+func tokenFromMetadata(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, authorizationKey)
+	if len(values) == 0 {
+		return ""
+	}
+
+	value := strings.TrimSpace(values[0])
+	if scheme, tokenString, ok := strings.Cut(value, " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(tokenString)
+	}
+	return value
+}
+
+// authenticatedStream overrides the context of a server stream with the one carrying the payload
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
@@ -0,0 +1,183 @@
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/fsobh/token"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer records the username of the payload each call was authenticated with
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	usernames chan string
+}
+
+func (server *healthServer) record(ctx context.Context) {
+	username := "anonymous"
+	if payload, ok := token.PayloadFromContext(ctx); ok {
+		username = payload.Username
+	}
+	server.usernames <- username
+}
+
+func (server *healthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	server.record(ctx)
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func (server *healthServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	server.record(stream.Context())
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+}
+
+func newTestServer(t *testing.T, maker token.Maker, opts ...Option) (*healthServer, *bufconn.Listener) {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(maker, opts...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(maker, opts...)),
+	)
+	health := &healthServer{usernames: make(chan string, 1)}
+	grpc_health_v1.RegisterHealthServer(server, health)
+
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return health, listener
+}
+
+func newTestClient(t *testing.T, listener *bufconn.Listener, source TokenSource) grpc_health_v1.HealthClient {
+	opts := []grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}
+	if source != nil {
+		opts = append(opts,
+			grpc.WithUnaryInterceptor(UnaryClientInterceptor(source)),
+			grpc.WithStreamInterceptor(StreamClientInterceptor(source)),
+		)
+	}
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestInterceptors(t *testing.T) {
+	ctx := context.Background()
+	maker, err := token.NewJWTMaker("0123456789abcdef0123456789abcdef")
+	require.NoError(t, err)
+
+	validToken, _, err := maker.CreateToken("alice", time.Minute)
+	require.NoError(t, err)
+	expiredToken, _, err := maker.CreateToken("alice", -time.Minute)
+	require.NoError(t, err)
+
+	watch := func(client grpc_health_v1.HealthClient) error {
+		stream, err := client.Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	t.Run("ValidToken", func(t *testing.T) {
+		health, listener := newTestServer(t, maker)
+		client := newTestClient(t, listener, StaticTokenSource(validToken))
+
+		_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		require.Equal(t, "alice", <-health.usernames)
+
+		require.NoError(t, watch(client))
+		require.Equal(t, "alice", <-health.usernames)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		_, listener := newTestServer(t, maker)
+
+		for name, test := range map[string]struct {
+			source  TokenSource
+			message string
+		}{
+			"Missing": {nil, token.ErrMissingToken.Error()},
+			"Expired": {StaticTokenSource(expiredToken), "token has expired"},
+			"Invalid": {StaticTokenSource("not-a-token"), token.ErrInvalidToken.Error()},
+		} {
+			t.Run(name, func(t *testing.T) {
+				client := newTestClient(t, listener, test.source)
+
+				_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Equal(t, test.message, status.Convert(err).Message())
+
+				err = watch(client)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Equal(t, test.message, status.Convert(err).Message())
+			})
+		}
+	})
+
+	t.Run("VerificationFailure", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			err  error
+			code codes.Code
+		}{
+			"StoreDown": {errors.New("connection refused"), codes.Unavailable},
+			"Deadline":  {context.DeadlineExceeded, codes.DeadlineExceeded},
+			"Canceled":  {context.Canceled, codes.Canceled},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, listener := newTestServer(t, token.NewRevocableMaker(maker, failingRevocationStore{test.err}))
+				client := newTestClient(t, listener, StaticTokenSource(validToken))
+
+				_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+				require.Equal(t, test.code, status.Code(err))
+
+				err = watch(client)
+				require.Equal(t, test.code, status.Code(err))
+			})
+		}
+	})
+
+	t.Run("OptionalMethods", func(t *testing.T) {
+		health, listener := newTestServer(t, maker, WithOptionalMethods(grpc_health_v1.Health_Check_FullMethodName))
+
+		_, err := newTestClient(t, listener, nil).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		require.Equal(t, "anonymous", <-health.usernames)
+
+		_, err = newTestClient(t, listener, StaticTokenSource(expiredToken)).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		err = watch(newTestClient(t, listener, nil))
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+// failingRevocationStore is a token.RevocationStore failing with err
+type failingRevocationStore struct {
+	err error
+}
+
+func (store failingRevocationStore) Revoke(context.Context, uuid.UUID, time.Time) error {
+	return store.err
+}
+
+func (store failingRevocationStore) IsRevoked(context.Context, uuid.UUID) (bool, error) {
+	return false, store.err
+}
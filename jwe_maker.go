@@ -0,0 +1,187 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// jweContentEncryption is the content encryption of every JWEMaker
+const jweContentEncryption = jose.A256GCM
+
+// JWEMaker creates and verifies encrypted JWTs (JWE, RFC 7516). The claims are only readable by holders of the
+// decryption key, and are signed before they are encrypted with WithNestedSigner, which is optional for a
+// shared key but required for ECDH-ES
+type JWEMaker struct {
+	keyAlgorithm  jose.KeyAlgorithm
+	encrypter     jose.Encrypter
+	decryptionKey interface{}
+	signer        ClaimsMaker
+	options
+}
+
+// NewJWEDirectMaker creates a maker encrypting tokens directly with a shared 32 byte key (dir + A256GCM)
+func NewJWEDirectMaker(key []byte, opts ...Option) (Maker, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("invalid key size : must be 32 bytes")
+	}
+	return newJWEMaker(jose.DIRECT, key, key, opts)
+}
+
+// NewJWEECDHMaker creates a maker encrypting tokens to an ECDSA public key (ECDH-ES+A256KW + A256GCM), which only
+// the holder of privateKey can decrypt. publicKey must belong to privateKey.
+// Anyone with the public key can encrypt a token, so the tokens must be signed: WithNestedSigner is required
+func NewJWEECDHMaker(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey, opts ...Option) (Maker, error) {
+	if privateKey == nil || publicKey == nil {
+		return nil, fmt.Errorf("ECDH private and public keys are required")
+	}
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return nil, fmt.Errorf("%w: ECDH public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
+	maker, err := newJWEMaker(jose.ECDH_ES_A256KW, publicKey, privateKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	if maker.signer == nil {
+		return nil, fmt.Errorf("ECDH-ES tokens must be signed, set a nested signer with WithNestedSigner")
+	}
+	return maker, nil
+}
+
+func newJWEMaker(keyAlgorithm jose.KeyAlgorithm, encryptionKey, decryptionKey interface{}, opts []Option) (*JWEMaker, error) {
+	maker := &JWEMaker{
+		keyAlgorithm:  keyAlgorithm,
+		decryptionKey: decryptionKey,
+		options:       newOptions(opts),
+	}
+
+	encrypterOptions := (&jose.EncrypterOptions{}).WithType("JWT")
+	if maker.options.signer != nil {
+		signer, err := asClaimsMaker(maker.options.signer)
+		if err != nil {
+			return nil, err
+		}
+		maker.signer = signer
+		encrypterOptions = encrypterOptions.WithContentType("JWT")
+	}
+
+	encrypter, err := jose.NewEncrypter(jweContentEncryption, jose.Recipient{
+		Algorithm: keyAlgorithm,
+		Key:       encryptionKey,
+		KeyID:     maker.keyID,
+	}, encrypterOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize JWE encrypter: %w", err)
+	}
+	maker.encrypter = encrypter
+
+	return maker, nil
+}
+
+func (maker *JWEMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+func (maker *JWEMaker) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.CreateTokenFromPayload(payload)
+	return token, payload, err
+}
+
+func (maker *JWEMaker) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.createTokenFromPayload(context.Background(), payload)
+}
+
+func (maker *JWEMaker) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	maker.options.stamp(payload)
+
+	var plaintext []byte
+	if maker.signer != nil {
+		signed, err := createTokenFromPayloadContext(ctx, maker.signer, payload)
+		if err != nil {
+			return "", fmt.Errorf("could not sign nested token: %w", err)
+		}
+		if strings.Count(signed, ".") != 2 {
+			return "", fmt.Errorf("nested tokens must be JWTs")
+		}
+		plaintext = []byte(signed)
+	} else {
+		var err error
+		if plaintext, err = json.Marshal(payload); err != nil {
+			return "", err
+		}
+	}
+
+	encrypted, err := maker.encrypter.Encrypt(plaintext)
+	if err != nil {
+		return "", fmt.Errorf("could not encrypt token: %w", err)
+	}
+	return encrypted.CompactSerialize()
+}
+
+func (maker *JWEMaker) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyTokenContext(context.Background(), token)
+}
+
+// CreateTokenContext creates a token, passing ctx on to the nested signer
+func (maker *JWEMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return createPayloadTokenContext(ctx, maker.options, username, duration, maker.createTokenFromPayload)
+}
+
+// VerifyTokenContext verifies a token, passing ctx on to the nested signer
+func (maker *JWEMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// only the maker's own algorithms are accepted
+	encrypted, err := jose.ParseEncryptedCompact(token, []jose.KeyAlgorithm{maker.keyAlgorithm}, []jose.ContentEncryption{jweContentEncryption})
+	if err != nil {
+		// go-jose doesn't export an error for a disallowed "alg" or "enc" header, so it's told apart by message
+		if strings.Contains(err.Error(), "unexpected key algorithm") || strings.Contains(err.Error(), "unexpected content encryption") {
+			return nil, newVerificationError(ReasonWrongAlgorithm, err)
+		}
+		return nil, newVerificationError(ReasonMalformed, err)
+	}
+
+	plaintext, err := encrypted.Decrypt(maker.decryptionKey)
+	if err != nil {
+		return nil, newVerificationError(ReasonBadSignature, err)
+	}
+
+	var payload *Payload
+	contentType, _ := encrypted.Header.ExtraHeaders[jose.HeaderContentType].(string)
+	switch {
+	case maker.signer != nil:
+		// a nested maker never accepts claims that weren't signed
+		if contentType != "JWT" {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+		if payload, err = AsContextMaker(maker.signer).VerifyTokenContext(ctx, string(plaintext)); err != nil {
+			return nil, err
+		}
+	case contentType != "":
+		return nil, newVerificationError(ReasonMalformed, nil)
+	default:
+		payload = &Payload{}
+		if err := json.Unmarshal(plaintext, payload); err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
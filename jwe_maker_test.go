@@ -0,0 +1,212 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestJWEMaker(t *testing.T) {
+	newKey := func(t *testing.T) []byte {
+		key := make([]byte, 32)
+		_, err := rand.Read(key)
+		require.NoError(t, err)
+		return key
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	directMaker, err := NewJWEDirectMaker(newKey(t))
+	require.NoError(t, err)
+	ecdhMaker, err := NewJWEECDHMaker(ecKey, &ecKey.PublicKey, WithNestedSigner(newTestAsymJWTMaker(t, "sig-1")))
+	require.NoError(t, err)
+
+	for name, maker := range map[string]Maker{"Direct": directMaker, "ECDH": ecdhMaker} {
+		t.Run(name, func(t *testing.T) {
+			token, payload, err := maker.(ClaimsMaker).CreateTokenWithClaims("alice", time.Minute, map[string]string{"email": "alice@example.com"})
+			require.NoError(t, err)
+
+			// Compact JWE, the claims can't be read from the token
+			require.Len(t, strings.Split(token, "."), 5)
+			for _, part := range strings.Split(token, ".") {
+				decoded, _ := base64.RawURLEncoding.DecodeString(part)
+				require.NotContains(t, string(decoded), "alice@example.com")
+			}
+
+			verifiedPayload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+
+			var claims struct {
+				Email string `json:"email"`
+			}
+			require.NoError(t, verifiedPayload.DecodeClaims(&claims))
+			require.Equal(t, "alice@example.com", claims.Email)
+
+			expiredToken, _, err := maker.CreateToken("alice", -time.Minute)
+			require.NoError(t, err)
+			_, err = maker.VerifyToken(expiredToken)
+			require.ErrorIs(t, err, ErrExpiredToken)
+
+			_, err = maker.VerifyToken("not.a.jwe.at.all")
+			require.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	t.Run("WrongKey", func(t *testing.T) {
+		otherDirect, err := NewJWEDirectMaker(newKey(t))
+		require.NoError(t, err)
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		otherECDH, err := NewJWEECDHMaker(otherKey, &otherKey.PublicKey, WithNestedSigner(newTestAsymJWTMaker(t, "sig-2")))
+		require.NoError(t, err)
+
+		token, _, err := directMaker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = otherDirect.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		// The key algorithm is pinned as well
+		_, err = ecdhMaker.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		token, _, err = ecdhMaker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = otherECDH.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		key := newKey(t)
+		signer, err := NewRSAJWTMaker("RS256", testRSAKey(), &testRSAKey().PublicKey, WithIssuer("auth.example.com"))
+		require.NoError(t, err)
+
+		nested, err := NewJWEDirectMaker(key, WithNestedSigner(signer), WithKeyID("enc-1"))
+		require.NoError(t, err)
+
+		token, payload, err := nested.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		var header map[string]interface{}
+		data, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &header))
+		require.Equal(t, "JWT", header["cty"])
+		require.Equal(t, "enc-1", header["kid"])
+
+		verifiedPayload, err := nested.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verifiedPayload.ID)
+		require.Equal(t, "auth.example.com", verifiedPayload.Issuer)
+
+		// Claims that were only encrypted aren't accepted by a nested maker, and the other way around
+		unsigned, err := NewJWEDirectMaker(key)
+		require.NoError(t, err)
+		unsignedToken, _, err := unsigned.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = nested.VerifyToken(unsignedToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		_, err = unsigned.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		// Nor are JWTs signed with another key
+		otherSigner, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+		forger, err := NewJWEDirectMaker(key, WithNestedSigner(otherSigner))
+		require.NoError(t, err)
+		forgedToken, _, err := forger.CreateToken("mallory", time.Minute)
+		require.NoError(t, err)
+		_, err = nested.VerifyToken(forgedToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("NestedKeySigner", func(t *testing.T) {
+		_, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		keySigner := &contextRecordingKeySigner{KeySigner: CryptoKeySigner(edPrivateKey)}
+		signer, err := NewAsymJWTMakerWithSigner(keySigner)
+		require.NoError(t, err)
+		maker, err := NewJWEDirectMaker(newKey(t), WithNestedSigner(signer))
+		require.NoError(t, err)
+
+		ctx := context.WithValue(context.Background(), contextKey{}, "request-1")
+		token, payload, err := AsContextMaker(maker).CreateTokenContext(ctx, "alice", time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, "request-1", keySigner.ctx.Value(contextKey{}))
+
+		verifiedPayload, err := maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verifiedPayload.ID)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, _, err = AsContextMaker(maker).CreateTokenContext(ctx, "alice", time.Minute)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("KeyValidation", func(t *testing.T) {
+		_, err := NewJWEDirectMaker([]byte("too short"))
+		require.Error(t, err)
+
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+		_, err = NewJWEECDHMaker(ecKey, &p384Key.PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		_, err = NewJWEECDHMaker(ecKey, &otherKey.PublicKey)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		_, err = NewJWEDirectMaker(newKey(t), WithNestedSigner(struct{ Maker }{directMaker}))
+		require.Error(t, err)
+
+		// ECDH-ES tokens can be encrypted by anyone with the public key, so they must be signed
+		_, err = NewJWEECDHMaker(ecKey, &ecKey.PublicKey)
+		require.Error(t, err)
+	})
+
+	t.Run("ForgedECDH", func(t *testing.T) {
+		// a token made with nothing but the public key
+		encrypter, err := jose.NewEncrypter(jweContentEncryption, jose.Recipient{
+			Algorithm: jose.ECDH_ES_A256KW,
+			Key:       &ecKey.PublicKey,
+		}, (&jose.EncrypterOptions{}).WithType("JWT"))
+		require.NoError(t, err)
+
+		payload, err := NewPayload("mallory", time.Hour)
+		require.NoError(t, err)
+		payload.Roles = []string{"admin"}
+		plaintext, err := json.Marshal(payload)
+		require.NoError(t, err)
+		encrypted, err := encrypter.Encrypt(plaintext)
+		require.NoError(t, err)
+		forgedToken, err := encrypted.CompactSerialize()
+		require.NoError(t, err)
+
+		_, err = ecdhMaker.VerifyToken(forgedToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+// contextRecordingKeySigner records the context it was last asked to sign under
+type contextRecordingKeySigner struct {
+	KeySigner
+	ctx context.Context
+}
+
+func (signer *contextRecordingKeySigner) Sign(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	signer.ctx = ctx
+	return signer.KeySigner.Sign(ctx, digest, opts)
+}
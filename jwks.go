@@ -0,0 +1,226 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+
+	"golang.org/x/crypto/ed25519"
+)
+
+// JWK is a JSON Web Key (RFC 7517) holding a public verification key
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set, as served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKSSource is implemented by makers and keyrings that can publish their public keys
+type JWKSSource interface {
+	JWKS() (*JWKS, error)
+}
+
+// NewEd25519JWK returns the OKP JWK (RFC 8037) of an Ed25519 public key
+func NewEd25519JWK(publicKey ed25519.PublicKey, keyID string) JWK {
+	return JWK{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(publicKey),
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: "EdDSA",
+	}
+}
+
+// Ed25519PublicKey returns the Ed25519 public key held by the JWK
+func (jwk JWK) Ed25519PublicKey() (ed25519.PublicKey, error) {
+	if jwk.KeyType != "OKP" || jwk.Curve != "Ed25519" {
+		return nil, fmt.Errorf("JWK is not an Ed25519 key: kty %q, crv %q", jwk.KeyType, jwk.Curve)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode JWK public key: %w", err)
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key size: %d", len(x))
+	}
+	return ed25519.PublicKey(x), nil
+}
+
+// NewRSAJWK returns the JWK of an RSA public key used with algorithm, RS256 or PS256
+func NewRSAJWK(publicKey *rsa.PublicKey, keyID, algorithm string) JWK {
+	return JWK{
+		KeyType:   "RSA",
+		N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: algorithm,
+	}
+}
+
+// NewECDSAJWK returns the EC JWK of a P-256 or P-384 public key
+func NewECDSAJWK(publicKey *ecdsa.PublicKey, keyID string) (JWK, error) {
+	var curve, algorithm string
+	switch publicKey.Curve {
+	case elliptic.P256():
+		curve, algorithm = "P-256", "ES256"
+	case elliptic.P384():
+		curve, algorithm = "P-384", "ES384"
+	default:
+		return JWK{}, fmt.Errorf("unsupported ECDSA curve %s", publicKey.Curve.Params().Name)
+	}
+
+	// coordinates are padded to the size of the curve (RFC 7518 section 6.2.1.2)
+	size := (publicKey.Curve.Params().BitSize + 7) / 8
+	return JWK{
+		KeyType:   "EC",
+		Curve:     curve,
+		X:         base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size))),
+		Y:         base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size))),
+		KeyID:     keyID,
+		Use:       "sig",
+		Algorithm: algorithm,
+	}, nil
+}
+
+// PublicKey returns the public key held by the JWK: an ed25519.PublicKey, *rsa.PublicKey or *ecdsa.PublicKey
+func (jwk JWK) PublicKey() (crypto.PublicKey, error) {
+	switch jwk.KeyType {
+	case "OKP":
+		return jwk.Ed25519PublicKey()
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode JWK modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode JWK exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA public key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported JWK curve %q", jwk.Curve)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode JWK x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode JWK y coordinate: %w", err)
+		}
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, fmt.Errorf("invalid ECDSA public key")
+		}
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWK key type %q", jwk.KeyType)
+	}
+}
+
+// JWKS returns the key set holding the maker's public key
+func (maker *RSAJWTMaker) JWKS() (*JWKS, error) {
+	return &JWKS{Keys: []JWK{NewRSAJWK(maker.publicKey, maker.keyID, maker.method.Alg())}}, nil
+}
+
+// JWKS returns the key set holding the maker's public key
+func (maker *ECDSAJWTMaker) JWKS() (*JWKS, error) {
+	jwk, err := NewECDSAJWK(maker.publicKey, maker.keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &JWKS{Keys: []JWK{jwk}}, nil
+}
+
+// JWKS returns the key set holding the maker's public key
+func (maker *AsymJWTMaker) JWKS() (*JWKS, error) {
+	return &JWKS{Keys: []JWK{NewEd25519JWK(maker.publicKey, maker.keyID)}}, nil
+}
+
+// JWKS returns the public keys of every key in the keyring that has one, active and verification-only alike.
+// Symmetric keys are left out
+func (keyring *Keyring) JWKS() (*JWKS, error) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	keyIDs := make([]string, 0, len(keyring.makers))
+	for keyID := range keyring.makers {
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Strings(keyIDs)
+
+	jwks := &JWKS{Keys: []JWK{}}
+	for _, keyID := range keyIDs {
+		source, ok := keyring.makers[keyID].(JWKSSource)
+		if !ok {
+			continue
+		}
+
+		keys, err := source.JWKS()
+		if err != nil {
+			return nil, err
+		}
+		jwks.Keys = append(jwks.Keys, keys.Keys...)
+	}
+	return jwks, nil
+}
+
+// JWKSHandler serves the key set of source, typically mounted at /.well-known/jwks.json. The key set
+// is read on every request, so keys rotated into a Keyring are published right away
+func JWKSHandler(source JWKSSource) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		jwks, err := source.JWKS()
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		body, err := json.Marshal(jwks)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(body)
+	})
+}
@@ -0,0 +1,254 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func newTestAsymJWTMaker(t *testing.T, keyID string) Maker {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	maker, err := NewAsymJWTMaker(privateKey, publicKey, WithKeyID(keyID))
+	require.NoError(t, err)
+	return maker
+}
+
+func TestJWKS(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	maker, err := NewAsymJWTMaker(privateKey, publicKey, WithKeyID("ed-1"))
+	require.NoError(t, err)
+
+	jwks, err := maker.(JWKSSource).JWKS()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 1)
+
+	data, err := json.Marshal(jwks.Keys[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"kty":"OKP","crv":"Ed25519","x":"`+jwks.Keys[0].X+`","kid":"ed-1","use":"sig","alg":"EdDSA"}`, string(data))
+
+	exported, err := jwks.Keys[0].Ed25519PublicKey()
+	require.NoError(t, err)
+	require.Equal(t, publicKey, exported)
+
+	_, err = JWK{KeyType: "RSA"}.Ed25519PublicKey()
+	require.Error(t, err)
+
+	t.Run("Keyring", func(t *testing.T) {
+		symmetric, err := NewJWTMaker(randomString(32), WithKeyID("hs-1"))
+		require.NoError(t, err)
+
+		keyring, err := NewKeyring(newTestAsymJWTMaker(t, "ed-2"), maker, symmetric)
+		require.NoError(t, err)
+
+		jwks, err := keyring.JWKS()
+		require.NoError(t, err)
+		require.Len(t, jwks.Keys, 2)
+		require.Equal(t, "ed-1", jwks.Keys[0].KeyID)
+		require.Equal(t, "ed-2", jwks.Keys[1].KeyID)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		JWKSHandler(maker.(JWKSSource)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+		var served JWKS
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served))
+		require.Equal(t, jwks, &served)
+
+		recorder = httptest.NewRecorder()
+		JWKSHandler(maker.(JWKSSource)).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/.well-known/jwks.json", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	})
+}
+
+func TestJWKSVerifier(t *testing.T) {
+	newServer := func(t *testing.T, source JWKSSource) (*httptest.Server, *int32) {
+		var fetches int32
+		handler := JWKSHandler(source)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			handler.ServeHTTP(w, r)
+		}))
+		t.Cleanup(server.Close)
+		return server, &fetches
+	}
+
+	t.Run("VerifyAndCache", func(t *testing.T) {
+		keyring, err := NewKeyring(newTestAsymJWTMaker(t, "ed-1"))
+		require.NoError(t, err)
+		server, fetches := newServer(t, keyring)
+
+		verifier, err := NewJWKSVerifier(server.URL)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			token, payload, err := keyring.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := verifier.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+		}
+		require.EqualValues(t, 1, atomic.LoadInt32(fetches))
+
+		expiredToken, _, err := keyring.CreateToken("alice", -time.Minute)
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(expiredToken)
+		require.ErrorIs(t, err, ErrExpiredToken)
+
+		_, _, err = verifier.CreateToken("alice", time.Minute)
+		require.ErrorIs(t, err, ErrVerificationOnly)
+	})
+
+	t.Run("RefreshOnUnknownKeyID", func(t *testing.T) {
+		keyring, err := NewKeyring(newTestAsymJWTMaker(t, "ed-1"))
+		require.NoError(t, err)
+		server, fetches := newServer(t, keyring)
+
+		verifier, err := NewJWKSVerifier(server.URL, WithRefreshInterval(50*time.Millisecond))
+		require.NoError(t, err)
+
+		token, _, err := keyring.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(token)
+		require.NoError(t, err)
+
+		require.NoError(t, keyring.Rotate(newTestAsymJWTMaker(t, "ed-2")))
+		token, _, err = keyring.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		// Too soon to refetch
+		_, err = verifier.VerifyToken(token)
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+		require.EqualValues(t, 1, atomic.LoadInt32(fetches))
+
+		time.Sleep(100 * time.Millisecond)
+		_, err = verifier.VerifyToken(token)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, atomic.LoadInt32(fetches))
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		maker := newTestAsymJWTMaker(t, "ed-1")
+		server, fetches := newServer(t, maker.(JWKSSource))
+
+		verifier, err := NewJWKSVerifier(server.URL)
+		require.NoError(t, err)
+
+		// Tokens signed with a key the endpoint doesn't publish can't trigger a fetch each
+		for i := 0; i < 10; i++ {
+			token, _, err := newTestAsymJWTMaker(t, "forged").CreateToken("mallory", time.Minute)
+			require.NoError(t, err)
+			_, err = verifier.VerifyToken(token)
+			require.ErrorIs(t, err, ErrUnknownKeyID)
+		}
+		require.EqualValues(t, 1, atomic.LoadInt32(fetches))
+	})
+
+	t.Run("UnavailableEndpoint", func(t *testing.T) {
+		var fetches int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			http.NotFound(w, r)
+		}))
+		t.Cleanup(server.Close)
+
+		verifier, err := NewJWKSVerifier(server.URL)
+		require.NoError(t, err)
+
+		token, _, err := newTestAsymJWTMaker(t, "ed-1").CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(token)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrInvalidToken)
+
+		// Too soon to refetch, the failure is reported again rather than the key being unknown
+		_, err = verifier.VerifyToken(token)
+		require.ErrorContains(t, err, "could not fetch JWKS")
+		require.NotErrorIs(t, err, ErrInvalidToken)
+		require.EqualValues(t, 1, atomic.LoadInt32(&fetches))
+
+		_, err = NewJWKSVerifier("")
+		require.Error(t, err)
+	})
+
+	t.Run("FetchBoundByContext", func(t *testing.T) {
+		// the endpoint hangs until the client gives up
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		t.Cleanup(server.Close)
+
+		verifier, err := NewJWKSVerifier(server.URL)
+		require.NoError(t, err)
+
+		token, _, err := newTestAsymJWTMaker(t, "ed-1").CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = verifier.(ContextMaker).VerifyTokenContext(ctx, token)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestJWKSVerifierRSAAndECDSA(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rs256, err := NewRSAJWTMaker("RS256", testRSAKey(), &testRSAKey().PublicKey, WithKeyID("rsa-1"))
+	require.NoError(t, err)
+	ps256, err := NewRSAJWTMaker("PS256", testRSAKey(), &testRSAKey().PublicKey, WithKeyID("rsa-pss-1"))
+	require.NoError(t, err)
+	es256, err := NewECDSAJWTMaker(p256Key, &p256Key.PublicKey, WithKeyID("ec-1"))
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(rs256, ps256, es256)
+	require.NoError(t, err)
+
+	jwks, err := keyring.JWKS()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 3)
+	for _, jwk := range jwks.Keys {
+		_, err := jwk.PublicKey()
+		require.NoError(t, err)
+	}
+
+	server := httptest.NewServer(JWKSHandler(keyring))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewJWKSVerifier(server.URL)
+	require.NoError(t, err)
+
+	for _, maker := range []Maker{rs256, ps256, es256} {
+		token, payload, err := maker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		verifiedPayload, err := verifier.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verifiedPayload.ID)
+	}
+
+	// A key is only accepted with the algorithm it was published for
+	rs256WithPSSKeyID, err := NewRSAJWTMaker("RS256", testRSAKey(), &testRSAKey().PublicKey, WithKeyID("rsa-pss-1"))
+	require.NoError(t, err)
+	token, _, err := rs256WithPSSKeyID.CreateToken("alice", time.Minute)
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,263 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/ed25519"
+)
+
+const (
+	// defaultRefreshInterval is the minimum time between two fetches of a remote key set
+	defaultRefreshInterval = time.Minute
+	// jwksMaxAge is how long a fetched key set is used before it's fetched again
+	jwksMaxAge = time.Hour
+	// maxJWKSSize caps the size of a fetched key set
+	maxJWKSSize = 1 << 20
+)
+
+// JWKSVerifier verifies EdDSA, RSA and ECDSA JWTs with the public keys published at a remote JWKS URL. The key set is
+// cached, and refetched when it gets old or a token carries an unknown key ID. Refetches are rate limited,
+// see WithRefreshInterval, so tokens with made up key IDs can't be used to hammer the JWKS endpoint
+type JWKSVerifier struct {
+	url string
+	options
+
+	mu          sync.RWMutex
+	keys        map[string]jwksKey
+	fetchedAt   time.Time
+	refreshMu   sync.Mutex
+	lastAttempt time.Time
+	lastErr     error // of the last attempt, returned again until the next one
+}
+
+// NewJWKSVerifier creates a verifier for the key set served at jwksURL. Nothing is fetched until the first
+// token is verified
+func NewJWKSVerifier(jwksURL string, opts ...Option) (Maker, error) {
+	if jwksURL == "" {
+		return nil, fmt.Errorf("JWKS URL is required")
+	}
+
+	verifier := &JWKSVerifier{
+		url:     jwksURL,
+		options: newOptions(opts),
+		keys:    make(map[string]jwksKey),
+	}
+	if verifier.httpClient == nil {
+		verifier.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if verifier.refreshInterval <= 0 {
+		verifier.refreshInterval = defaultRefreshInterval
+	}
+	return verifier, nil
+}
+
+// CreateToken always fails, a JWKSVerifier has no private key
+func (verifier *JWKSVerifier) CreateToken(string, time.Duration) (string, *Payload, error) {
+	return "", nil, ErrVerificationOnly
+}
+
+// CreateTokenContext always fails, a JWKSVerifier has no private key
+func (verifier *JWKSVerifier) CreateTokenContext(context.Context, string, time.Duration) (string, *Payload, error) {
+	return "", nil, ErrVerificationOnly
+}
+
+// VerifyToken verifies a token, see VerifyTokenContext
+func (verifier *JWKSVerifier) VerifyToken(token string) (*Payload, error) {
+	return verifier.VerifyTokenContext(context.Background(), token)
+}
+
+// VerifyTokenContext verifies a token, ctx bounds the fetch of the key set when it has to be refreshed
+func (verifier *JWKSVerifier) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var keyErr error
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		keyID, _ := token.Header["kid"].(string)
+
+		var key jwksKey
+		if key, keyErr = verifier.publicKey(ctx, keyID); keyErr != nil {
+			return nil, keyErr
+		}
+
+		// each key is pinned to the one algorithm it was published for
+		if token.Method == nil || token.Method.Alg() != key.method.Alg() {
+			keyErr = newVerificationError(ReasonWrongAlgorithm, nil)
+			return nil, keyErr
+		}
+		return key.publicKey, nil
+	}
+
+	parsedToken, err := jwtParser.ParseWithClaims(token, &Payload{}, keyFunc)
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if err != nil {
+		return nil, jwtVerificationError(err)
+	}
+
+	payload, ok := parsedToken.Claims.(*Payload)
+	if !ok {
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	if err := verifier.options.verify(payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// publicKey returns the key with the given ID, refreshing the key set when the key is unknown or the set is stale
+func (verifier *JWKSVerifier) publicKey(ctx context.Context, keyID string) (jwksKey, error) {
+	publicKey, found, fresh := verifier.cachedKey(keyID)
+	if found && fresh {
+		return publicKey, nil
+	}
+
+	if err := verifier.refresh(ctx); err != nil {
+		// keep using what we have while the JWKS endpoint is unavailable
+		if found {
+			return publicKey, nil
+		}
+		return jwksKey{}, err
+	}
+
+	publicKey, found, _ = verifier.cachedKey(keyID)
+	if !found {
+		if keyID == "" {
+			return jwksKey{}, errMissingKeyID()
+		}
+		return jwksKey{}, newVerificationError(ReasonUnknownKey, nil)
+	}
+	return publicKey, nil
+}
+
+// cachedKey looks a key up in the cached key set. A token without a key ID is accepted when the set holds a single key
+func (verifier *JWKSVerifier) cachedKey(keyID string) (publicKey jwksKey, found, fresh bool) {
+	verifier.mu.RLock()
+	defer verifier.mu.RUnlock()
+
+	publicKey, found = verifier.keys[keyID]
+	if !found && keyID == "" && len(verifier.keys) == 1 {
+		for _, key := range verifier.keys {
+			publicKey, found = key, true
+		}
+	}
+	return publicKey, found, time.Since(verifier.fetchedAt) < jwksMaxAge
+}
+
+// refresh fetches the key set, unless it was already attempted within the refresh interval, in which case the
+// error of that attempt is returned
+func (verifier *JWKSVerifier) refresh(ctx context.Context) error {
+	verifier.refreshMu.Lock()
+	defer verifier.refreshMu.Unlock()
+
+	if time.Since(verifier.lastAttempt) < verifier.refreshInterval {
+		if verifier.lastErr != nil {
+			return fmt.Errorf("could not fetch JWKS: %w", verifier.lastErr)
+		}
+		return nil
+	}
+	verifier.lastAttempt = time.Now()
+
+	keys, err := verifier.fetch(ctx)
+	verifier.lastErr = err
+	if err != nil {
+		return fmt.Errorf("could not fetch JWKS: %w", err)
+	}
+
+	verifier.mu.Lock()
+	verifier.keys = keys
+	verifier.fetchedAt = time.Now()
+	verifier.mu.Unlock()
+	return nil
+}
+
+// fetch downloads the key set and returns its signing keys by key ID
+func (verifier *JWKSVerifier) fetch(ctx context.Context) (map[string]jwksKey, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, verifier.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := verifier.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	var jwks JWKS
+	if err := json.NewDecoder(io.LimitReader(response.Body, maxJWKSSize)).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]jwksKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// keys of other types may be published alongside ours
+		key, err := newJWKSKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+	return keys, nil
+}
+
+// jwksKey is a public key from a key set and the algorithm it verifies
+type jwksKey struct {
+	publicKey crypto.PublicKey
+	method    jwt.SigningMethod
+}
+
+func newJWKSKey(jwk JWK) (jwksKey, error) {
+	publicKey, err := jwk.PublicKey()
+	if err != nil {
+		return jwksKey{}, err
+	}
+
+	var method jwt.SigningMethod
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	case *ecdsa.PublicKey:
+		method = jwt.SigningMethodES256
+		if key.Curve == elliptic.P384() {
+			method = jwt.SigningMethodES384
+		}
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeySize {
+			return jwksKey{}, fmt.Errorf("RSA key is too short")
+		}
+		switch jwk.Algorithm {
+		case "", "RS256":
+			method = jwt.SigningMethodRS256
+		case "PS256":
+			method = jwt.SigningMethodPS256
+		}
+	}
+	if method == nil || (jwk.Algorithm != "" && jwk.Algorithm != method.Alg()) {
+		return jwksKey{}, fmt.Errorf("unsupported JWK algorithm %q", jwk.Algorithm)
+	}
+
+	return jwksKey{publicKey: publicKey, method: method}, nil
+}
@@ -1,7 +1,8 @@
 package token
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"github.com/golang-jwt/jwt"
 	"golang.org/x/crypto/ed25519"
 	"time"
@@ -10,23 +11,66 @@ import (
 type AsymJWTMaker struct {
 	privateKey ed25519.PrivateKey
 	publicKey  ed25519.PublicKey
+	keySigner  KeySigner
+	options
 }
 
-func NewAsymJWTMaker(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) (Maker, error) {
+func NewAsymJWTMaker(privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey, opts ...Option) (Maker, error) {
+	if len(privateKey) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid key size : Ed25519 private keys must be %d bytes", ed25519.PrivateKeySize)
+	}
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	if !publicKey.Equal(privateKey.Public()) {
+		return nil, fmt.Errorf("%w: Ed25519 public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
 	return &AsymJWTMaker{
 		privateKey: privateKey,
 		publicKey:  publicKey,
+		options:    newOptions(opts),
 	}, nil
 }
 
+// NewAsymJWTVerifier creates an EdDSA maker that only verifies tokens, with nothing but the public key
+func NewAsymJWTVerifier(publicKey ed25519.PublicKey, opts ...Option) (Maker, error) {
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	return &AsymJWTMaker{publicKey: publicKey, options: newOptions(opts)}, nil
+}
+
+// NewAsymJWTMakerWithSigner creates an EdDSA maker signing through signer, which holds an Ed25519 key
+func NewAsymJWTMakerWithSigner(signer KeySigner, opts ...Option) (Maker, error) {
+	publicKey, err := keySignerPublicKey[ed25519.PublicKey](signer, "Ed25519")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEd25519PublicKey(publicKey); err != nil {
+		return nil, err
+	}
+	return &AsymJWTMaker{publicKey: publicKey, keySigner: signer, options: newOptions(opts)}, nil
+}
+
+func checkEd25519PublicKey(publicKey ed25519.PublicKey) error {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid key size : Ed25519 public keys must be %d bytes", ed25519.PublicKeySize)
+	}
+	return nil
+}
+
 func (maker *AsymJWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+func (maker *AsymJWTMaker) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
 	if err != nil {
 		return "", payload, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, payload)
-	signedToken, err := token.SignedString(maker.privateKey)
+	signedToken, err := maker.CreateTokenFromPayload(payload)
 	if err != nil {
 		return "", payload, err
 	}
@@ -34,29 +78,20 @@ func (maker *AsymJWTMaker) CreateToken(username string, duration time.Duration)
 	return signedToken, payload, nil
 }
 
-func (maker *AsymJWTMaker) VerifyToken(token string) (*Payload, error) {
-	keyFunc := func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodEd25519); !ok {
-			return nil, ErrExpiredToken
-		}
-		return maker.publicKey, nil
-	}
+func (maker *AsymJWTMaker) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.createTokenFromPayload(context.Background(), payload)
+}
 
-	parsedToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
-	if err != nil {
-		var verr *jwt.ValidationError
-		if errors.As(err, &verr) {
-			if errors.Is(verr.Inner, ErrExpiredToken) {
-				return nil, ErrExpiredToken
-			}
-		}
-		return nil, ErrInvalidToken
+func (maker *AsymJWTMaker) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	if maker.keySigner != nil {
+		return signJWTWithKeySigner(ctx, payload, jwt.SigningMethodEdDSA, maker.keySigner, maker.options)
 	}
-
-	payload, ok := parsedToken.Claims.(*Payload)
-	if !ok {
-		return nil, ErrInvalidToken
+	if maker.privateKey == nil {
+		return "", ErrVerificationOnly
 	}
+	return signJWT(payload, jwt.SigningMethodEdDSA, maker.privateKey, maker.options)
+}
 
-	return payload, nil
+func (maker *AsymJWTMaker) VerifyToken(token string) (*Payload, error) {
+	return verifyJWT(token, jwt.SigningMethodEdDSA, maker.publicKey, maker.options)
 }
@@ -58,7 +58,7 @@ func TestAsymJWTMaker(t *testing.T) {
 		// Verify should fail
 		verifiedPayload, err := maker.VerifyToken(token)
 		require.Error(t, err)
-		require.Equal(t, ErrExpiredToken, err)
+		require.ErrorIs(t, err, ErrExpiredToken)
 		require.Nil(t, verifiedPayload)
 	})
 
@@ -69,13 +69,13 @@ func TestAsymJWTMaker(t *testing.T) {
 		// Try to verify invalid token
 		verifiedPayload, err := maker.VerifyToken("invalid.token.format")
 		require.Error(t, err)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
 		require.Nil(t, verifiedPayload)
 
 		// Try to verify empty token
 		verifiedPayload, err = maker.VerifyToken("")
 		require.Error(t, err)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
 		require.Nil(t, verifiedPayload)
 	})
 
@@ -98,7 +98,7 @@ func TestAsymJWTMaker(t *testing.T) {
 		// Verify should fail because public key doesn't match
 		verifiedPayload, err := wrongMaker.VerifyToken(token)
 		require.Error(t, err)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
 		require.Nil(t, verifiedPayload)
 	})
 
@@ -115,7 +115,8 @@ func TestAsymJWTMaker(t *testing.T) {
 		// Verify should fail because of wrong signing method
 		verifiedPayload, err := maker.VerifyToken(token)
 		require.Error(t, err)
-		require.Equal(t, ErrExpiredToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.Equal(t, ReasonWrongAlgorithm, VerificationReasonOf(err))
 		require.Nil(t, verifiedPayload)
 	})
 }
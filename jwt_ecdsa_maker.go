@@ -0,0 +1,138 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// ECDSAJWTMaker creates and verifies JWTs signed with ECDSA, ES256 on P-256 or ES384 on P-384
+type ECDSAJWTMaker struct {
+	method     jwt.SigningMethod
+	privateKey *ecdsa.PrivateKey
+	publicKey  *ecdsa.PublicKey
+	keySigner  KeySigner
+	options
+}
+
+// NewECDSAJWTMaker creates an ECDSA maker. The algorithm follows from the curve of the keys: ES256 for P-256
+// and ES384 for P-384, other curves are rejected
+func NewECDSAJWTMaker(privateKey *ecdsa.PrivateKey, publicKey *ecdsa.PublicKey, opts ...Option) (Maker, error) {
+	if privateKey == nil || publicKey == nil {
+		return nil, fmt.Errorf("ECDSA private and public keys are required")
+	}
+	if privateKey.Curve != publicKey.Curve {
+		return nil, fmt.Errorf("ECDSA private and public keys are on different curves")
+	}
+
+	maker, err := newECDSAJWTVerifier(publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return nil, fmt.Errorf("%w: ECDSA public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
+	maker.privateKey = privateKey
+	return maker, nil
+}
+
+// NewECDSAJWTVerifier creates an ECDSA maker that only verifies tokens, with nothing but the public key.
+// The algorithm follows from the key's curve, as with NewECDSAJWTMaker
+func NewECDSAJWTVerifier(publicKey *ecdsa.PublicKey, opts ...Option) (Maker, error) {
+	return newECDSAJWTVerifier(publicKey, opts)
+}
+
+// NewECDSAJWTMakerWithSigner creates an ECDSA maker signing through signer, which holds a P-256 or P-384 key
+func NewECDSAJWTMakerWithSigner(signer KeySigner, opts ...Option) (Maker, error) {
+	publicKey, err := keySignerPublicKey[*ecdsa.PublicKey](signer, "ECDSA")
+	if err != nil {
+		return nil, err
+	}
+
+	maker, err := newECDSAJWTVerifier(publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	maker.keySigner = signer
+	return maker, nil
+}
+
+func newECDSAJWTVerifier(publicKey *ecdsa.PublicKey, opts []Option) (*ECDSAJWTMaker, error) {
+	if publicKey == nil {
+		return nil, fmt.Errorf("ECDSA public key is required")
+	}
+
+	var method jwt.SigningMethod
+	switch publicKey.Curve {
+	case elliptic.P256():
+		method = jwt.SigningMethodES256
+	case elliptic.P384():
+		method = jwt.SigningMethodES384
+	default:
+		return nil, fmt.Errorf("unsupported ECDSA curve %s, must be P-256 or P-384", publicKey.Curve.Params().Name)
+	}
+
+	return &ECDSAJWTMaker{
+		method:    method,
+		publicKey: publicKey,
+		options:   newOptions(opts),
+	}, nil
+}
+
+// NewECDSAJWTMakerFromPEM creates an ECDSA maker from PEM encoded keys, SEC 1 or PKCS #8 for the private key
+// and PKIX for the public key
+func NewECDSAJWTMakerFromPEM(privateKeyPEM, publicKeyPEM []byte, opts ...Option) (Maker, error) {
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ECDSA private key: %w", err)
+	}
+
+	publicKey, err := jwt.ParseECPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ECDSA public key: %w", err)
+	}
+
+	return NewECDSAJWTMaker(privateKey, publicKey, opts...)
+}
+
+// Algorithm returns the JWS algorithm the maker signs with
+func (maker *ECDSAJWTMaker) Algorithm() string {
+	return maker.method.Alg()
+}
+
+func (maker *ECDSAJWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+func (maker *ECDSAJWTMaker) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.CreateTokenFromPayload(payload)
+	return token, payload, err
+}
+
+func (maker *ECDSAJWTMaker) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.createTokenFromPayload(context.Background(), payload)
+}
+
+func (maker *ECDSAJWTMaker) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	if maker.keySigner != nil {
+		return signJWTWithKeySigner(ctx, payload, maker.method, maker.keySigner, maker.options)
+	}
+	if maker.privateKey == nil {
+		return "", ErrVerificationOnly
+	}
+	return signJWT(payload, maker.method, maker.privateKey, maker.options)
+}
+
+func (maker *ECDSAJWTMaker) VerifyToken(token string) (*Payload, error) {
+	return verifyJWT(token, maker.method, maker.publicKey, maker.options)
+}
@@ -0,0 +1,93 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestECDSAJWTMaker(t *testing.T) {
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("CreateAndVerifyToken", func(t *testing.T) {
+		for algorithm, key := range map[string]*ecdsa.PrivateKey{"ES256": p256Key, "ES384": p384Key} {
+			maker, err := NewECDSAJWTMaker(key, &key.PublicKey)
+			require.NoError(t, err)
+			require.Equal(t, algorithm, maker.(*ECDSAJWTMaker).Algorithm())
+
+			token, payload, err := maker.CreateToken("test_user", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+
+			expiredToken, _, err := maker.CreateToken("test_user", -time.Minute)
+			require.NoError(t, err)
+			_, err = maker.VerifyToken(expiredToken)
+			require.ErrorIs(t, err, ErrExpiredToken)
+		}
+	})
+
+	t.Run("AlgorithmPinning", func(t *testing.T) {
+		es256, err := NewECDSAJWTMaker(p256Key, &p256Key.PublicKey)
+		require.NoError(t, err)
+		es384, err := NewECDSAJWTMaker(p384Key, &p384Key.PublicKey)
+		require.NoError(t, err)
+
+		token, _, err := es384.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+		_, err = es256.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		rs256, err := NewRSAJWTMaker("RS256", testRSAKey(), &testRSAKey().PublicKey)
+		require.NoError(t, err)
+		token, _, err = rs256.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+		_, err = es256.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("KeyValidation", func(t *testing.T) {
+		_, err := NewECDSAJWTMaker(p256Key, &p384Key.PublicKey)
+		require.Error(t, err)
+
+		_, err = NewECDSAJWTMaker(nil, &p256Key.PublicKey)
+		require.Error(t, err)
+
+		p224Key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+		require.NoError(t, err)
+		_, err = NewECDSAJWTMaker(p224Key, &p224Key.PublicKey)
+		require.Error(t, err)
+	})
+
+	t.Run("FromPEM", func(t *testing.T) {
+		der, err := x509.MarshalECPrivateKey(p256Key)
+		require.NoError(t, err)
+		privatePEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPKIX(t, &p256Key.PublicKey)})
+
+		maker, err := NewECDSAJWTMakerFromPEM(privatePEM, publicPEM)
+		require.NoError(t, err)
+
+		token, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+
+		direct, err := NewECDSAJWTMaker(p256Key, &p256Key.PublicKey)
+		require.NoError(t, err)
+		_, err = direct.VerifyToken(token)
+		require.NoError(t, err)
+
+		_, err = NewECDSAJWTMakerFromPEM(privatePEM, []byte("not a key"))
+		require.Error(t, err)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/require"
 )
 
@@ -48,6 +49,19 @@ func TestJWTMaker(t *testing.T) {
 		require.ErrorIs(t, err, ErrExpiredToken)
 		require.Nil(t, payload2)
 	})
+
+	t.Run("WrongAlgorithm", func(t *testing.T) {
+		payload, err := NewPayload(username, duration)
+		require.NoError(t, err)
+
+		// signed with the maker's own secret, but not with HS256
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, payload).SignedString([]byte(randomString(32)))
+		require.NoError(t, err)
+
+		_, err = maker.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.Equal(t, ReasonWrongAlgorithm, VerificationReasonOf(err))
+	})
 }
 
 // Helper function to generate random string
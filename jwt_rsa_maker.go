@@ -0,0 +1,142 @@
+package token
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// minRSAKeySize is the smallest RSA modulus, in bits, an RSAJWTMaker accepts
+const minRSAKeySize = 2048
+
+// RSAJWTMaker creates and verifies JWTs signed with RSA, either PKCS #1 v1.5 (RS256) or PSS (PS256)
+type RSAJWTMaker struct {
+	method     jwt.SigningMethod
+	privateKey *rsa.PrivateKey
+	publicKey  *rsa.PublicKey
+	keySigner  KeySigner
+	options
+}
+
+// NewRSAJWTMaker creates an RSA maker for algorithm, "RS256" or "PS256". Keys must be at least 2048 bits long
+func NewRSAJWTMaker(algorithm string, privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, opts ...Option) (Maker, error) {
+	if privateKey == nil {
+		return nil, fmt.Errorf("RSA private and public keys are required")
+	}
+	maker, err := newRSAJWTVerifier(algorithm, publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey.N.BitLen() < minRSAKeySize {
+		return nil, fmt.Errorf("invalid key size : RSA keys must be at least %d bits", minRSAKeySize)
+	}
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return nil, fmt.Errorf("%w: RSA public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
+	maker.privateKey = privateKey
+	return maker, nil
+}
+
+// NewRSAJWTVerifier creates an RSA maker for algorithm, "RS256" or "PS256", that only verifies tokens,
+// with nothing but the public key
+func NewRSAJWTVerifier(algorithm string, publicKey *rsa.PublicKey, opts ...Option) (Maker, error) {
+	return newRSAJWTVerifier(algorithm, publicKey, opts)
+}
+
+// NewRSAJWTMakerWithSigner creates an RSA maker for algorithm, "RS256" or "PS256", signing through signer,
+// which holds an RSA key
+func NewRSAJWTMakerWithSigner(algorithm string, signer KeySigner, opts ...Option) (Maker, error) {
+	publicKey, err := keySignerPublicKey[*rsa.PublicKey](signer, "RSA")
+	if err != nil {
+		return nil, err
+	}
+
+	maker, err := newRSAJWTVerifier(algorithm, publicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	maker.keySigner = signer
+	return maker, nil
+}
+
+func newRSAJWTVerifier(algorithm string, publicKey *rsa.PublicKey, opts []Option) (*RSAJWTMaker, error) {
+	var method jwt.SigningMethod
+	switch algorithm {
+	case "RS256":
+		method = jwt.SigningMethodRS256
+	case "PS256":
+		method = jwt.SigningMethodPS256
+	default:
+		return nil, fmt.Errorf("unsupported RSA algorithm %q, must be RS256 or PS256", algorithm)
+	}
+
+	if publicKey == nil {
+		return nil, fmt.Errorf("RSA public key is required")
+	}
+	if publicKey.N.BitLen() < minRSAKeySize {
+		return nil, fmt.Errorf("invalid key size : RSA keys must be at least %d bits", minRSAKeySize)
+	}
+
+	return &RSAJWTMaker{
+		method:    method,
+		publicKey: publicKey,
+		options:   newOptions(opts),
+	}, nil
+}
+
+// NewRSAJWTMakerFromPEM creates an RSA maker from PEM encoded keys, PKCS #1 or PKCS #8 for the private key
+// and PKIX or PKCS #1 for the public key
+func NewRSAJWTMakerFromPEM(algorithm string, privateKeyPEM, publicKeyPEM []byte, opts ...Option) (Maker, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse RSA private key: %w", err)
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse RSA public key: %w", err)
+	}
+
+	return NewRSAJWTMaker(algorithm, privateKey, publicKey, opts...)
+}
+
+// Algorithm returns the JWS algorithm the maker signs with
+func (maker *RSAJWTMaker) Algorithm() string {
+	return maker.method.Alg()
+}
+
+func (maker *RSAJWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+func (maker *RSAJWTMaker) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
+	if err != nil {
+		return "", payload, err
+	}
+
+	token, err := maker.CreateTokenFromPayload(payload)
+	return token, payload, err
+}
+
+func (maker *RSAJWTMaker) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.createTokenFromPayload(context.Background(), payload)
+}
+
+func (maker *RSAJWTMaker) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	if maker.keySigner != nil {
+		return signJWTWithKeySigner(ctx, payload, maker.method, maker.keySigner, maker.options)
+	}
+	if maker.privateKey == nil {
+		return "", ErrVerificationOnly
+	}
+	return signJWT(payload, maker.method, maker.privateKey, maker.options)
+}
+
+func (maker *RSAJWTMaker) VerifyToken(token string) (*Payload, error) {
+	return verifyJWT(token, maker.method, maker.publicKey, maker.options)
+}
@@ -0,0 +1,108 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSAJWTMaker(t *testing.T) {
+	privateKey := testRSAKey()
+	publicKey := &privateKey.PublicKey
+
+	t.Run("CreateAndVerifyToken", func(t *testing.T) {
+		for _, algorithm := range []string{"RS256", "PS256"} {
+			maker, err := NewRSAJWTMaker(algorithm, privateKey, publicKey)
+			require.NoError(t, err)
+			require.Equal(t, algorithm, maker.(*RSAJWTMaker).Algorithm())
+
+			token, payload, err := maker.CreateToken("test_user", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+			require.Equal(t, "test_user", verifiedPayload.Username)
+
+			expiredToken, _, err := maker.CreateToken("test_user", -time.Minute)
+			require.NoError(t, err)
+			_, err = maker.VerifyToken(expiredToken)
+			require.ErrorIs(t, err, ErrExpiredToken)
+		}
+	})
+
+	t.Run("AlgorithmPinning", func(t *testing.T) {
+		rs256, err := NewRSAJWTMaker("RS256", privateKey, publicKey)
+		require.NoError(t, err)
+		ps256, err := NewRSAJWTMaker("PS256", privateKey, publicKey)
+		require.NoError(t, err)
+
+		// Same key, other RSA padding
+		token, _, err := ps256.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+		_, err = rs256.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		// HMAC keyed with the public key, the classic algorithm confusion attack
+		payload, err := NewPayload("test_user", time.Minute)
+		require.NoError(t, err)
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPKIX(t, publicKey)})
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString(publicPEM)
+		require.NoError(t, err)
+		_, err = rs256.VerifyToken(forged)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		// Unsigned
+		unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, payload).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+		_, err = rs256.VerifyToken(unsigned)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("KeyValidation", func(t *testing.T) {
+		_, err := NewRSAJWTMaker("RS512", privateKey, publicKey)
+		require.Error(t, err)
+
+		_, err = NewRSAJWTMaker("RS256", nil, publicKey)
+		require.Error(t, err)
+
+		weakKey, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+		_, err = NewRSAJWTMaker("RS256", weakKey, &weakKey.PublicKey)
+		require.Error(t, err)
+	})
+
+	t.Run("FromPEM", func(t *testing.T) {
+		privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: mustMarshalPKIX(t, publicKey)})
+
+		maker, err := NewRSAJWTMakerFromPEM("RS256", privatePEM, publicPEM)
+		require.NoError(t, err)
+
+		token, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+
+		// The keys are the same as the ones used directly
+		direct, err := NewRSAJWTMaker("RS256", privateKey, publicKey)
+		require.NoError(t, err)
+		_, err = direct.VerifyToken(token)
+		require.NoError(t, err)
+
+		_, err = NewRSAJWTMakerFromPEM("RS256", []byte("not a key"), publicPEM)
+		require.Error(t, err)
+		_, err = NewRSAJWTMakerFromPEM("RS256", privatePEM, []byte("not a key"))
+		require.Error(t, err)
+	})
+}
+
+func mustMarshalPKIX(t *testing.T, publicKey interface{}) []byte {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	return der
+}
@@ -0,0 +1,47 @@
+package token
+
+import (
+	"github.com/golang-jwt/jwt"
+)
+
+// jwtParser parses JWTs without checking their claims: jwt-go would call Payload.Valid, which reads the
+// system clock with no leeway, so the maker's verify checks them instead
+var jwtParser = &jwt.Parser{SkipClaimsValidation: true}
+
+// signJWT signs a payload with the given method and key, writing the maker's key ID to the "kid" header
+func signJWT(payload *Payload, method jwt.SigningMethod, key interface{}, o options) (string, error) {
+	o.stamp(payload)
+
+	jwtToken := jwt.NewWithClaims(method, payload)
+	if o.keyID != "" {
+		jwtToken.Header["kid"] = o.keyID
+	}
+	return jwtToken.SignedString(key)
+}
+
+// verifyJWT checks a JWT signed with method and key. The algorithm is pinned: a token whose "alg" header
+// names any other algorithm is rejected before its signature is looked at
+func verifyJWT(token string, method jwt.SigningMethod, key interface{}, o options) (*Payload, error) {
+	keyFunc := func(jwtToken *jwt.Token) (interface{}, error) {
+		if jwtToken.Method == nil || jwtToken.Method.Alg() != method.Alg() {
+			return nil, newVerificationError(ReasonWrongAlgorithm, nil)
+		}
+		return key, nil
+	}
+
+	jwtToken, err := jwtParser.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		return nil, jwtVerificationError(err)
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	if err := o.verify(payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
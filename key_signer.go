@@ -0,0 +1,164 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/ed25519"
+)
+
+// KeySigner signs with a private key kept out of the process's memory, in a KMS, an HSM or a signing agent.
+// It is crypto.Signer with a context, CryptoKeySigner adapts a crypto.Signer
+type KeySigner interface {
+
+	// Public returns the public half of the signing key
+	Public() crypto.PublicKey
+
+	// Sign signs digest, the message hashed with opts.HashFunc(). Ed25519 keys are given the message itself
+	// and crypto.Hash(0). ECDSA signatures are ASN.1 encoded, as crypto.Signer returns them
+	Sign(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+}
+
+// CryptoKeySigner adapts a crypto.Signer, e.g. from a KMS client library or a PKCS #11 module, to a KeySigner.
+// The context is only checked before signing
+func CryptoKeySigner(signer crypto.Signer) KeySigner {
+	return cryptoKeySigner{signer: signer}
+}
+
+type cryptoKeySigner struct {
+	signer crypto.Signer
+}
+
+func (signer cryptoKeySigner) Public() crypto.PublicKey {
+	return signer.signer.Public()
+}
+
+func (signer cryptoKeySigner) Sign(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return signer.signer.Sign(rand.Reader, digest, opts)
+}
+
+// keySignerPublicKey returns the public key of signer, which must be a K
+func keySignerPublicKey[K crypto.PublicKey](signer KeySigner, kind string) (K, error) {
+	var publicKey K
+	if signer == nil {
+		return publicKey, fmt.Errorf("key signer is required")
+	}
+	publicKey, ok := signer.Public().(K)
+	if !ok {
+		return publicKey, fmt.Errorf("key signer holds a %T, not an %s key", signer.Public(), kind)
+	}
+	return publicKey, nil
+}
+
+// signJWTWithKeySigner signs a JWT through a KeySigner, jwt-go can only sign with keys held in memory
+func signJWTWithKeySigner(ctx context.Context, payload *Payload, method jwt.SigningMethod, signer KeySigner, o options) (string, error) {
+	o.stamp(payload)
+
+	jwtToken := jwt.NewWithClaims(method, payload)
+	if o.keyID != "" {
+		jwtToken.Header["kid"] = o.keyID
+	}
+	signingString, err := jwtToken.SigningString()
+	if err != nil {
+		return "", err
+	}
+
+	var signature []byte
+	switch method.Alg() {
+	case "EdDSA":
+		signature, err = signEd25519(ctx, signer, []byte(signingString))
+	case "RS256":
+		signature, err = signHashed(ctx, signer, crypto.SHA256, crypto.SHA256, []byte(signingString))
+	case "PS256":
+		pss := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+		signature, err = signHashed(ctx, signer, crypto.SHA256, pss, []byte(signingString))
+	case "ES256":
+		signature, err = signECDSA(ctx, signer, crypto.SHA256, 32, []byte(signingString))
+	case "ES384":
+		signature, err = signECDSA(ctx, signer, crypto.SHA384, 48, []byte(signingString))
+	default:
+		return "", fmt.Errorf("unsupported signing method %s", method.Alg())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return signingString + "." + jwt.EncodeSegment(signature), nil
+}
+
+// encodePublicPaseto assembles a signed PASETO token from its parts (PASETO section 4.2 and friends)
+func encodePublicPaseto(protocol paseto.Protocol, message, signature, footer []byte) string {
+	encoded := protocol.Header() + base64.RawURLEncoding.EncodeToString(append(append([]byte(nil), message...), signature...))
+	if len(footer) > 0 {
+		encoded += "." + base64.RawURLEncoding.EncodeToString(footer)
+	}
+	return encoded
+}
+
+// pae is PASETO's pre-authentication encoding of the pieces a token's signature covers
+func pae(pieces ...[]byte) []byte {
+	encoded := binary.LittleEndian.AppendUint64(nil, uint64(len(pieces)))
+	for _, piece := range pieces {
+		encoded = binary.LittleEndian.AppendUint64(encoded, uint64(len(piece)))
+		encoded = append(encoded, piece...)
+	}
+	return encoded
+}
+
+func signEd25519(ctx context.Context, signer KeySigner, message []byte) ([]byte, error) {
+	signature, err := signer.Sign(ctx, message, crypto.Hash(0))
+	if err != nil {
+		return nil, fmt.Errorf("could not sign token: %w", err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("could not sign token: Ed25519 signature is %d bytes long", len(signature))
+	}
+	return signature, nil
+}
+
+// signHashed hashes message and has signer sign the digest
+func signHashed(ctx context.Context, signer KeySigner, hash crypto.Hash, opts crypto.SignerOpts, message []byte) ([]byte, error) {
+	digest := hash.New()
+	digest.Write(message)
+
+	signature, err := signer.Sign(ctx, digest.Sum(nil), opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign token: %w", err)
+	}
+	return signature, nil
+}
+
+// signECDSA signs message and converts the ASN.1 signature to the fixed size r || s that JWS and PASETO use
+func signECDSA(ctx context.Context, signer KeySigner, hash crypto.Hash, size int, message []byte) ([]byte, error) {
+	der, err := signHashed(ctx, signer, hash, hash, message)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		R, S *big.Int
+	}
+	if rest, err := asn1.Unmarshal(der, &parsed); err != nil || len(rest) > 0 {
+		return nil, fmt.Errorf("could not sign token: invalid ECDSA signature")
+	}
+	if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 || parsed.R.BitLen() > size*8 || parsed.S.BitLen() > size*8 {
+		return nil, fmt.Errorf("could not sign token: ECDSA signature doesn't fit the curve")
+	}
+
+	signature := make([]byte, 2*size)
+	parsed.R.FillBytes(signature[:size])
+	parsed.S.FillBytes(signature[size:])
+	return signature, nil
+}
@@ -0,0 +1,125 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestKeySigner(t *testing.T) {
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	v3PublicKey, err := paseto.NewV3AsymmetricPublicKeyFromEcdsa(p384Key.PublicKey)
+	require.NoError(t, err)
+
+	type pair struct {
+		maker    func() (Maker, error)
+		verifier func() (Maker, error)
+	}
+	pairs := map[string]pair{
+		"AsymJWT": {
+			func() (Maker, error) { return NewAsymJWTMakerWithSigner(CryptoKeySigner(edPrivateKey)) },
+			func() (Maker, error) { return NewAsymJWTVerifier(edPublicKey) },
+		},
+		"RS256": {
+			func() (Maker, error) { return NewRSAJWTMakerWithSigner("RS256", CryptoKeySigner(testRSAKey())) },
+			func() (Maker, error) { return NewRSAJWTVerifier("RS256", &testRSAKey().PublicKey) },
+		},
+		"PS256": {
+			func() (Maker, error) { return NewRSAJWTMakerWithSigner("PS256", CryptoKeySigner(testRSAKey())) },
+			func() (Maker, error) { return NewRSAJWTVerifier("PS256", &testRSAKey().PublicKey) },
+		},
+		"ES256": {
+			func() (Maker, error) { return NewECDSAJWTMakerWithSigner(CryptoKeySigner(p256Key)) },
+			func() (Maker, error) { return NewECDSAJWTVerifier(&p256Key.PublicKey) },
+		},
+		"ES384": {
+			func() (Maker, error) { return NewECDSAJWTMakerWithSigner(CryptoKeySigner(p384Key)) },
+			func() (Maker, error) { return NewECDSAJWTVerifier(&p384Key.PublicKey) },
+		},
+		"PasetoV2Public": {
+			func() (Maker, error) { return NewPasetoV2PublicWithSigner(CryptoKeySigner(edPrivateKey)) },
+			func() (Maker, error) { return NewPasetoV2PublicVerifier(hex.EncodeToString(edPublicKey)) },
+		},
+		"PasetoV3Public": {
+			func() (Maker, error) { return NewPasetoV3PublicWithSigner(CryptoKeySigner(p384Key)) },
+			func() (Maker, error) { return NewPasetoV3PublicVerifier(v3PublicKey.ExportHex()) },
+		},
+		"PasetoV4Public": {
+			func() (Maker, error) { return NewPasetoV4PublicWithSigner(CryptoKeySigner(edPrivateKey)) },
+			func() (Maker, error) { return NewPasetoV4PublicVerifier(hex.EncodeToString(edPublicKey)) },
+		},
+	}
+
+	for name, pair := range pairs {
+		t.Run(name, func(t *testing.T) {
+			maker, err := pair.maker()
+			require.NoError(t, err)
+			verifier, err := pair.verifier()
+			require.NoError(t, err)
+
+			token, payload, err := maker.CreateToken("test_user", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := verifier.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+			require.Equal(t, "test_user", verifiedPayload.Username)
+
+			verifiedPayload, err = maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, _, err = AsContextMaker(maker).CreateTokenContext(ctx, "test_user", time.Minute)
+			require.ErrorIs(t, err, context.Canceled)
+		})
+	}
+
+	t.Run("Binding", func(t *testing.T) {
+		v3, err := NewPasetoV3PublicWithSigner(CryptoKeySigner(p384Key), WithFooter(map[string]string{"tenant": "acme"}))
+		require.NoError(t, err)
+		v4, err := NewPasetoV4PublicWithSigner(CryptoKeySigner(edPrivateKey), WithFooter(map[string]string{"tenant": "acme"}))
+		require.NoError(t, err)
+
+		for _, maker := range []BindingMaker{v3, v4} {
+			binding := Binding{ImplicitAssertion: []byte("tenant-42")}
+			payload, err := NewPayload("test_user", time.Minute)
+			require.NoError(t, err)
+
+			token, err := maker.CreateBoundToken(payload, binding)
+			require.NoError(t, err)
+
+			_, err = maker.VerifyBoundToken(token, binding)
+			require.NoError(t, err)
+			_, err = maker.VerifyBoundToken(token, Binding{ImplicitAssertion: []byte("tenant-43")})
+			require.ErrorIs(t, err, ErrInvalidToken)
+		}
+	})
+
+	t.Run("WrongKeyType", func(t *testing.T) {
+		_, err := NewAsymJWTMakerWithSigner(CryptoKeySigner(p256Key))
+		require.Error(t, err)
+		_, err = NewRSAJWTMakerWithSigner("RS256", CryptoKeySigner(edPrivateKey))
+		require.Error(t, err)
+		_, err = NewECDSAJWTMakerWithSigner(nil)
+		require.Error(t, err)
+		_, err = NewPasetoV3PublicWithSigner(CryptoKeySigner(p256Key))
+		require.Error(t, err)
+		_, err = NewPasetoV4PublicWithSigner(CryptoKeySigner(testRSAKey()))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,148 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Keys holds key material by name, e.g. the hex encoded keys and PASERKs the constructors take
+type Keys map[string][]byte
+
+// Get returns the key called name with surrounding whitespace trimmed, as key files usually end with a newline
+func (keys Keys) Get(name string) (string, error) {
+	key, ok := keys[name]
+	if !ok {
+		return "", fmt.Errorf("key %q not found", name)
+	}
+	trimmed := strings.TrimSpace(string(key))
+	if trimmed == "" {
+		return "", fmt.Errorf("key %q is empty", name)
+	}
+	return trimmed, nil
+}
+
+// Names returns the names of the keys, sorted
+func (keys Keys) Names() []string {
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// KeySource supplies the keys makers are built from. Sources are read again on every reload,
+// see ReloadingMaker
+type KeySource interface {
+	Keys(ctx context.Context) (Keys, error)
+}
+
+// KeySourceFunc adapts a function to a KeySource
+type KeySourceFunc func(ctx context.Context) (Keys, error)
+
+// Keys calls f
+func (f KeySourceFunc) Keys(ctx context.Context) (Keys, error) {
+	return f(ctx)
+}
+
+// StaticKeySource returns a KeySource that always supplies the same keys
+func StaticKeySource(keys map[string]string) KeySource {
+	static := make(Keys, len(keys))
+	for name, key := range keys {
+		static[name] = []byte(key)
+	}
+
+	return KeySourceFunc(func(ctx context.Context) (Keys, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return cloneKeys(static), nil
+	})
+}
+
+// EnvKeySource returns a KeySource reading each key from the environment variable of the same name.
+// Every variable must be set
+func EnvKeySource(names ...string) KeySource {
+	return KeySourceFunc(func(ctx context.Context) (Keys, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		keys := make(Keys, len(names))
+		for _, name := range names {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return nil, fmt.Errorf("environment variable %s is not set", name)
+			}
+			keys[name] = []byte(value)
+		}
+		return keys, nil
+	})
+}
+
+// FileKeySource returns a KeySource reading the key called name from the file at path
+func FileKeySource(name, path string) KeySource {
+	return KeySourceFunc(func(ctx context.Context) (Keys, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read key file: %w", err)
+		}
+		return Keys{name: data}, nil
+	})
+}
+
+// DirectoryKeySource returns a KeySource reading every file in dir as a key named after the file, as a
+// Kubernetes secret is mounted. Hidden files are skipped, and with them the "..data" links Kubernetes swaps
+// to update a mounted secret
+func DirectoryKeySource(dir string) KeySource {
+	return KeySourceFunc(func(ctx context.Context) (Keys, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("could not read key directory: %w", err)
+		}
+
+		keys := make(Keys)
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			// the files of a mounted secret are symlinks, stat follows them
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("could not read key file: %w", err)
+			}
+			if !info.Mode().IsRegular() {
+				continue
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("could not read key file: %w", err)
+			}
+			keys[entry.Name()] = data
+		}
+		return keys, nil
+	})
+}
+
+func cloneKeys(keys Keys) Keys {
+	clone := make(Keys, len(keys))
+	for name, key := range keys {
+		clone[name] = append([]byte(nil), key...)
+	}
+	return clone
+}
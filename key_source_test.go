@@ -0,0 +1,97 @@
+package token
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySources(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Static", func(t *testing.T) {
+		source := StaticKeySource(map[string]string{"secret": "abc\n"})
+		keys, err := source.Keys(ctx)
+		require.NoError(t, err)
+
+		secret, err := keys.Get("secret")
+		require.NoError(t, err)
+		require.Equal(t, "abc", secret)
+
+		// the caller can't change what the source supplies next
+		keys["secret"][0] = 'x'
+		keys, err = source.Keys(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "abc\n", string(keys["secret"]))
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("TOKEN_TEST_PRIVATE_KEY", "private")
+		t.Setenv("TOKEN_TEST_PUBLIC_KEY", "public")
+
+		keys, err := EnvKeySource("TOKEN_TEST_PRIVATE_KEY", "TOKEN_TEST_PUBLIC_KEY").Keys(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"TOKEN_TEST_PRIVATE_KEY", "TOKEN_TEST_PUBLIC_KEY"}, keys.Names())
+
+		_, err = EnvKeySource("TOKEN_TEST_UNSET_KEY").Keys(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secret")
+		require.NoError(t, os.WriteFile(path, []byte("abc\n"), 0o600))
+
+		keys, err := FileKeySource("secret", path).Keys(ctx)
+		require.NoError(t, err)
+		secret, err := keys.Get("secret")
+		require.NoError(t, err)
+		require.Equal(t, "abc", secret)
+
+		_, err = FileKeySource("secret", filepath.Join(t.TempDir(), "missing")).Keys(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("Directory", func(t *testing.T) {
+		// laid out like a mounted Kubernetes secret
+		dir := t.TempDir()
+		version := filepath.Join(dir, "..2026_10_18_12_00_00.000000001")
+		require.NoError(t, os.Mkdir(version, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(version, "private"), []byte("private"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(version, "public"), []byte("public"), 0o600))
+		require.NoError(t, os.Symlink(filepath.Base(version), filepath.Join(dir, "..data")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "private"), filepath.Join(dir, "private")))
+		require.NoError(t, os.Symlink(filepath.Join("..data", "public"), filepath.Join(dir, "public")))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("hidden"), 0o600))
+		require.NoError(t, os.Mkdir(filepath.Join(dir, "nested"), 0o700))
+
+		keys, err := DirectoryKeySource(dir).Keys(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"private", "public"}, keys.Names())
+		require.Equal(t, "private", string(keys["private"]))
+
+		_, err = DirectoryKeySource(filepath.Join(dir, "missing")).Keys(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		keys := Keys{"blank": []byte(" \n")}
+
+		_, err := keys.Get("blank")
+		require.Error(t, err)
+		_, err = keys.Get("missing")
+		require.Error(t, err)
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := StaticKeySource(nil).Keys(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		_, err = DirectoryKeySource(t.TempDir()).Keys(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
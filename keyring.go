@@ -0,0 +1,223 @@
+package token
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors returned by a Keyring
+var (
+	ErrUnknownKeyID = fmt.Errorf("%w: unknown key ID", ErrInvalidToken)
+	ErrMissingKeyID = fmt.Errorf("%w: token has no key ID", ErrInvalidToken)
+)
+
+// pasetoHeader matches the "version.purpose." prefix of a PASETO token
+var pasetoHeader = regexp.MustCompile(`^v[1-4]\.(local|public)\.`)
+
+// tokenHeader holds the JWT header fields we look at before verification
+type tokenHeader struct {
+	KeyID string `json:"kid"`
+}
+
+// Keyring is a Maker backed by one active signing key plus any number of verification-only keys.
+// Every key is a KeyedMaker, tokens are created by the active one and verified by the one whose ID
+// matches the token's "kid". Keys can be rotated while the keyring is in use.
+type Keyring struct {
+	mu       sync.RWMutex
+	activeID string
+	makers   map[string]Maker
+}
+
+// NewKeyring creates a keyring signing with active, that also accepts tokens from the verification-only makers
+func NewKeyring(active Maker, verificationOnly ...Maker) (*Keyring, error) {
+	keyring := &Keyring{makers: make(map[string]Maker)}
+
+	for _, maker := range verificationOnly {
+		if err := keyring.AddVerificationKey(maker); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := keyring.Rotate(active); err != nil {
+		return nil, err
+	}
+
+	return keyring, nil
+}
+
+// Rotate makes maker the active signing key. The previously active key stays available for
+// verification until it is removed with RemoveKey
+func (keyring *Keyring) Rotate(maker Maker) error {
+	keyID, err := makerKeyID(maker)
+	if err != nil {
+		return err
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	keyring.makers[keyID] = maker
+	keyring.activeID = keyID
+	return nil
+}
+
+// AddVerificationKey adds a key that is only used to verify tokens
+func (keyring *Keyring) AddVerificationKey(maker Maker) error {
+	keyID, err := makerKeyID(maker)
+	if err != nil {
+		return err
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if keyID == keyring.activeID {
+		return fmt.Errorf("key %q is the active signing key", keyID)
+	}
+	keyring.makers[keyID] = maker
+	return nil
+}
+
+// RemoveKey retires a verification key, tokens carrying its ID are rejected from then on
+func (keyring *Keyring) RemoveKey(keyID string) error {
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if keyID == keyring.activeID {
+		return fmt.Errorf("cannot remove the active signing key %q", keyID)
+	}
+	delete(keyring.makers, keyID)
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key new tokens are signed with
+func (keyring *Keyring) ActiveKeyID() string {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	return keyring.activeID
+}
+
+// KeyID returns the ID of the active signing key
+func (keyring *Keyring) KeyID() string {
+	return keyring.ActiveKeyID()
+}
+
+func (keyring *Keyring) active() Maker {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	return keyring.makers[keyring.activeID]
+}
+
+// makerOptions returns the options of the active key, see optionsOf
+func (keyring *Keyring) makerOptions() options {
+	return optionsOf(keyring.active())
+}
+
+// CreateToken creates a token with the active signing key
+func (keyring *Keyring) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return keyring.active().CreateToken(username, duration)
+}
+
+// CreateTokenContext creates a token with the active signing key
+func (keyring *Keyring) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return AsContextMaker(keyring.active()).CreateTokenContext(ctx, username, duration)
+}
+
+// CreateTokenWithClaims creates a token carrying custom claims with the active signing key
+func (keyring *Keyring) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	maker, err := asClaimsMaker(keyring.active())
+	if err != nil {
+		return "", nil, err
+	}
+	return maker.CreateTokenWithClaims(username, duration, claims)
+}
+
+// CreateTokenFromPayload creates a token for a payload built by the caller with the active signing key
+func (keyring *Keyring) CreateTokenFromPayload(payload *Payload) (string, error) {
+	maker, err := asClaimsMaker(keyring.active())
+	if err != nil {
+		return "", err
+	}
+	return maker.CreateTokenFromPayload(payload)
+}
+
+// VerifyToken verifies the token with the key named by its "kid"
+func (keyring *Keyring) VerifyToken(token string) (*Payload, error) {
+	return keyring.VerifyTokenContext(context.Background(), token)
+}
+
+// VerifyTokenContext verifies the token with the key named by its "kid"
+func (keyring *Keyring) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	keyID, err := tokenKeyID(token)
+	if err != nil {
+		return nil, err
+	}
+
+	keyring.mu.RLock()
+	maker, ok := keyring.makers[keyID]
+	keyring.mu.RUnlock()
+
+	if !ok {
+		return nil, newVerificationError(ReasonUnknownKey, nil)
+	}
+	return AsContextMaker(maker).VerifyTokenContext(ctx, token)
+}
+
+func makerKeyID(maker Maker) (string, error) {
+	keyed, ok := maker.(KeyedMaker)
+	if !ok || keyed.KeyID() == "" {
+		return "", fmt.Errorf("maker %T has no key ID, create it with WithKeyID", maker)
+	}
+	return keyed.KeyID(), nil
+}
+
+func asClaimsMaker(maker Maker) (ClaimsMaker, error) {
+	claimsMaker, ok := maker.(ClaimsMaker)
+	if !ok {
+		return nil, fmt.Errorf("maker %T does not support custom claims", maker)
+	}
+	return claimsMaker, nil
+}
+
+// errMissingKeyID is returned for a token a keyring can't pick a key for, because it doesn't name one
+func errMissingKeyID() error {
+	return &VerificationError{Reason: ReasonUnknownKey, Err: ErrMissingKeyID}
+}
+
+// tokenKeyID reads the "kid" of a JWT header or PASETO footer, without verifying the token
+func tokenKeyID(token string) (string, error) {
+	parts := strings.Split(token, ".")
+
+	var encoded string
+	switch {
+	case pasetoHeader.MatchString(token) && len(parts) == 4:
+		encoded = parts[3]
+	case pasetoHeader.MatchString(token):
+		return "", errMissingKeyID()
+	case len(parts) == 3 || len(parts) == 5:
+		// JWS and JWE compact serializations both start with the protected header
+		encoded = parts[0]
+	default:
+		return "", newVerificationError(ReasonMalformed, nil)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", newVerificationError(ReasonMalformed, nil)
+	}
+
+	var header tokenHeader
+	if err := json.Unmarshal(data, &header); err != nil || header.KeyID == "" {
+		return "", errMissingKeyID()
+	}
+
+	return header.KeyID, nil
+}
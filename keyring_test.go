@@ -0,0 +1,147 @@
+package token
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	newV4Local := func(t *testing.T, keyID string) Maker {
+		maker, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex(), WithKeyID(keyID))
+		require.NoError(t, err)
+		return maker
+	}
+
+	t.Run("Rotate", func(t *testing.T) {
+		keyring, err := NewKeyring(newV4Local(t, "2024-01"))
+		require.NoError(t, err)
+		require.Equal(t, "2024-01", keyring.ActiveKeyID())
+
+		oldToken, oldPayload, err := keyring.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, keyring.Rotate(newV4Local(t, "2024-02")))
+		require.Equal(t, "2024-02", keyring.ActiveKeyID())
+
+		newToken, newPayload, err := keyring.CreateToken("bob", time.Minute)
+		require.NoError(t, err)
+
+		keyID, err := tokenKeyID(newToken)
+		require.NoError(t, err)
+		require.Equal(t, "2024-02", keyID)
+
+		// Tokens from both keys verify
+		verifiedPayload, err := keyring.VerifyToken(oldToken)
+		require.NoError(t, err)
+		require.Equal(t, oldPayload.ID, verifiedPayload.ID)
+
+		verifiedPayload, err = keyring.VerifyToken(newToken)
+		require.NoError(t, err)
+		require.Equal(t, newPayload.ID, verifiedPayload.ID)
+
+		// Until the old key is retired
+		require.NoError(t, keyring.RemoveKey("2024-01"))
+		_, err = keyring.VerifyToken(oldToken)
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		require.Error(t, keyring.RemoveKey("2024-02"))
+	})
+
+	t.Run("VerificationOnlyKeys", func(t *testing.T) {
+		secret := randomString(32)
+		legacy, err := NewJWTMaker(secret, WithKeyID("legacy"))
+		require.NoError(t, err)
+
+		keyring, err := NewKeyring(newV4Local(t, "current"), legacy)
+		require.NoError(t, err)
+
+		// The JWT key is never used for signing, but its tokens still verify
+		legacyToken, _, err := legacy.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		verifiedPayload, err := keyring.VerifyToken(legacyToken)
+		require.NoError(t, err)
+		require.Equal(t, "alice", verifiedPayload.Username)
+
+		token, _, err := keyring.CreateToken("bob", time.Minute)
+		require.NoError(t, err)
+		require.Regexp(t, `^v4\.local\.`, token)
+
+		require.Error(t, keyring.AddVerificationKey(newV4Local(t, "current")))
+	})
+
+	t.Run("EveryMakerCarriesKeyID", func(t *testing.T) {
+		for name, maker := range newTestMakers(t, WithKeyID("kid-1")) {
+			t.Run(name, func(t *testing.T) {
+				keyring, err := NewKeyring(maker)
+				require.NoError(t, err)
+
+				token, payload, err := keyring.CreateToken("alice", time.Minute)
+				require.NoError(t, err)
+
+				keyID, err := tokenKeyID(token)
+				require.NoError(t, err)
+				require.Equal(t, "kid-1", keyID)
+
+				verifiedPayload, err := keyring.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, payload.ID, verifiedPayload.ID)
+			})
+		}
+	})
+
+	t.Run("MissingKeyID", func(t *testing.T) {
+		_, err := NewKeyring(struct{ Maker }{newV4Local(t, "a")})
+		require.Error(t, err)
+
+		unkeyed, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+		_, err = NewKeyring(unkeyed)
+		require.Error(t, err)
+
+		keyring, err := NewKeyring(newV4Local(t, "a"))
+		require.NoError(t, err)
+
+		token, _, err := unkeyed.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		_, err = keyring.VerifyToken(token)
+		require.ErrorIs(t, err, ErrMissingKeyID)
+
+		_, err = keyring.VerifyToken("not-a-token")
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("ConcurrentRotation", func(t *testing.T) {
+		keyring, err := NewKeyring(newV4Local(t, "key-0"))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					token, _, err := keyring.CreateToken("alice", time.Minute)
+					assert.NoError(t, err)
+					_, err = keyring.VerifyToken(token)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+
+		for i := 1; i <= 20; i++ {
+			require.NoError(t, keyring.Rotate(newV4Local(t, fmt.Sprintf("key-%d", i))))
+		}
+		wg.Wait()
+
+		require.Equal(t, "key-20", keyring.ActiveKeyID())
+	})
+}
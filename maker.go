@@ -1,6 +1,18 @@
 package token
 
-import "time"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrVerificationOnly is returned when a maker that only holds public keys is asked to create a token
+	ErrVerificationOnly = errors.New("maker can only verify tokens")
+
+	// ErrKeyMismatch is returned when a maker is given a public key that doesn't belong to its private key
+	ErrKeyMismatch = errors.New("key pair mismatch")
+)
 
 // Maker interface will be used to manage the token creation and verification
 // We will be creating support for both JWT and PASETO tokens
@@ -12,3 +24,53 @@ type Maker interface {
 	// VerifyToken Check if the input token is valid or not
 	VerifyToken(token string) (*Payload, error)
 }
+
+// Signer is the token creating half of a Maker
+type Signer interface {
+
+	// CreateToken Create a token for a specific username with a duration
+	CreateToken(username string, duration time.Duration) (string, *Payload, error)
+}
+
+// Verifier is the token verifying half of a Maker, all a service that only accepts tokens needs.
+// The verifier constructors (NewAsymJWTVerifier, NewPasetoV4PublicVerifier, ...) build makers that
+// hold a public key only, their Create methods fail with ErrVerificationOnly
+type Verifier interface {
+
+	// VerifyToken Check if the input token is valid or not
+	VerifyToken(token string) (*Payload, error)
+}
+
+// ClaimsMaker is a Maker whose tokens can carry custom and registered claims next to the standard ones.
+// The custom claims of a verified token are available through Payload.DecodeClaims
+type ClaimsMaker interface {
+	Maker
+
+	// CreateTokenWithClaims Create a token for a specific username with a duration and custom claims
+	CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error)
+
+	// CreateTokenFromPayload Create a token for a payload built by the caller (e.g. to set its subject or not-before time).
+	// The maker's configured issuer and audience are filled in when the payload doesn't set them
+	CreateTokenFromPayload(payload *Payload) (string, error)
+}
+
+// KeyedMaker is a Maker whose tokens carry the ID of the key that created them (see WithKeyID)
+type KeyedMaker interface {
+	Maker
+
+	// KeyID The ID of the maker's key, empty if it has none
+	KeyID() string
+}
+
+// ContextMaker is a Maker whose token creation and verification take a context, so deadlines, cancellation and
+// tracing reach the stores and key sources a maker consults (revocation stores, JWKS endpoints, ...).
+// Every maker in this package implements it, AsContextMaker adapts any other Maker
+type ContextMaker interface {
+	Maker
+
+	// CreateTokenContext Create a token for a specific username with a duration
+	CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error)
+
+	// VerifyTokenContext Check if the input token is valid or not
+	VerifyTokenContext(ctx context.Context, token string) (*Payload, error)
+}
@@ -0,0 +1,659 @@
+package token
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"golang.org/x/crypto/ed25519"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidMakerConfig is returned for a MakerConfig that can't be built, wrapped with every problem found
+var ErrInvalidMakerConfig = errors.New("invalid maker config")
+
+// MakerConfig declares a maker, so the token format can be chosen per environment without code changes.
+// It is read from JSON or YAML with ParseMakerConfig or LoadMakerConfig, or from environment variables with
+// MakerConfigFromEnv, and built with NewMakerFromConfig
+type MakerConfig struct {
+
+	// Type is "jwt" or "paseto"
+	Type string `json:"type"`
+
+	// Algorithm is the JWT signing algorithm: HS256 (the default), EdDSA, RS256, PS256, ES256 or ES384
+	Algorithm string `json:"algorithm,omitempty"`
+
+	// Version is the PASETO version, "v2", "v3" or "v4" (the default), and Purpose "local" (the default) or "public"
+	Version string `json:"version,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+
+	// SecretKey is the key of HS256 and local PASETO makers. PrivateKey and PublicKey are the key pair of the
+	// other makers, the public key is derived when left out, and a maker given only a public key is a verifier.
+	// PASETO keys are hex or PASERK encoded, JWT keys PEM encoded (Ed25519 keys may be hex encoded too)
+	SecretKey  KeyRef `json:"secret_key,omitempty"`
+	PrivateKey KeyRef `json:"private_key,omitempty"`
+	PublicKey  KeyRef `json:"public_key,omitempty"`
+
+	KeyID            string   `json:"key_id,omitempty"`
+	Issuer           string   `json:"issuer,omitempty"`
+	Audience         []string `json:"audience,omitempty"`
+	ExpectedIssuer   string   `json:"expected_issuer,omitempty"`
+	ExpectedAudience string   `json:"expected_audience,omitempty"`
+
+	// TTL is the lifetime of the tokens created by ConfiguredMaker.IssueToken
+	TTL Duration `json:"ttl"`
+
+	// Leeway is the clock skew tolerated when verifying tokens, see WithLeeway
+	Leeway Duration `json:"leeway,omitempty"`
+
+	// Revocation names the RevocationStore the maker is wrapped with, see RevocableMaker. "memory" is a
+	// MemoryRevocationStore, other stores are registered with WithConfigRevocationStore. Empty for no revocation
+	Revocation string `json:"revocation,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "15m" in configs
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string such as "15m" or "1h30m"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("duration must be a string such as \"15m\"")
+	}
+	duration, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// KeyRef references key material instead of holding it, so configs can be checked in. It is "env:NAME" for
+// the environment variable NAME, "file:PATH" for the file at PATH, or "value:KEY" for the key itself
+type KeyRef string
+
+// Resolve reads the referenced key, with surrounding whitespace trimmed
+func (ref KeyRef) Resolve() (string, error) {
+	scheme, value, _ := strings.Cut(string(ref), ":")
+
+	var key string
+	switch scheme {
+	case "env":
+		env, ok := os.LookupEnv(value)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", value)
+		}
+		key = env
+	case "file":
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("could not read key file: %w", err)
+		}
+		key = string(data)
+	case "value":
+		key = value
+	default:
+		return "", fmt.Errorf("key reference must start with env:, file: or value:")
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("key is empty")
+	}
+	return key, nil
+}
+
+func (ref KeyRef) validate() error {
+	scheme, value, _ := strings.Cut(string(ref), ":")
+	if scheme != "env" && scheme != "file" && scheme != "value" {
+		return fmt.Errorf("must start with env:, file: or value:")
+	}
+	if value == "" {
+		return fmt.Errorf("names no key")
+	}
+	return nil
+}
+
+// ParseMakerConfig decodes a YAML or JSON maker config. Unknown fields are rejected, so typos don't go unnoticed
+func ParseMakerConfig(data []byte) (MakerConfig, error) {
+	// YAML is a superset of JSON, the document is converted to JSON to decode it with the same rules either way
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return MakerConfig{}, fmt.Errorf("%w: %w", ErrInvalidMakerConfig, err)
+	}
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return MakerConfig{}, fmt.Errorf("%w: %w", ErrInvalidMakerConfig, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+
+	var config MakerConfig
+	if err := decoder.Decode(&config); err != nil {
+		return MakerConfig{}, fmt.Errorf("%w: %w", ErrInvalidMakerConfig, err)
+	}
+	return config, nil
+}
+
+// LoadMakerConfig reads a YAML or JSON maker config from the file at path
+func LoadMakerConfig(path string) (MakerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return MakerConfig{}, fmt.Errorf("could not read maker config: %w", err)
+	}
+	return ParseMakerConfig(data)
+}
+
+// MakerConfigFromEnv reads a maker config from the environment variables named after its fields with prefix,
+// e.g. TOKEN_TYPE, TOKEN_SECRET_KEY and TOKEN_TTL for the prefix "TOKEN". Audience is a comma-separated list
+func MakerConfigFromEnv(prefix string) (MakerConfig, error) {
+	env := func(name string) string {
+		return os.Getenv(prefix + "_" + name)
+	}
+
+	config := MakerConfig{
+		Type:             env("TYPE"),
+		Algorithm:        env("ALGORITHM"),
+		Version:          env("VERSION"),
+		Purpose:          env("PURPOSE"),
+		SecretKey:        KeyRef(env("SECRET_KEY")),
+		PrivateKey:       KeyRef(env("PRIVATE_KEY")),
+		PublicKey:        KeyRef(env("PUBLIC_KEY")),
+		KeyID:            env("KEY_ID"),
+		Issuer:           env("ISSUER"),
+		ExpectedIssuer:   env("EXPECTED_ISSUER"),
+		ExpectedAudience: env("EXPECTED_AUDIENCE"),
+		Revocation:       env("REVOCATION"),
+	}
+	if audience := env("AUDIENCE"); audience != "" {
+		for _, entry := range strings.Split(audience, ",") {
+			config.Audience = append(config.Audience, strings.TrimSpace(entry))
+		}
+	}
+
+	var problems []error
+	durations := []struct {
+		name     string
+		duration *Duration
+	}{{"TTL", &config.TTL}, {"LEEWAY", &config.Leeway}}
+	for _, entry := range durations {
+		name, duration := entry.name, entry.duration
+		text := env(name)
+		if text == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(text)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s_%s: %w", prefix, name, err))
+			continue
+		}
+		*duration = Duration(parsed)
+	}
+	if len(problems) > 0 {
+		return MakerConfig{}, fmt.Errorf("%w: %w", ErrInvalidMakerConfig, errors.Join(problems...))
+	}
+
+	return config, nil
+}
+
+// withDefaults fills in the algorithm, version and purpose left out
+func (config MakerConfig) withDefaults() MakerConfig {
+	switch config.Type {
+	case "jwt":
+		if config.Algorithm == "" {
+			config.Algorithm = "HS256"
+		}
+	case "paseto":
+		if config.Version == "" {
+			config.Version = "v4"
+		}
+		if config.Purpose == "" {
+			config.Purpose = "local"
+		}
+	}
+	return config
+}
+
+// symmetric reports whether the configured maker uses a secret key rather than a key pair
+func (config MakerConfig) symmetric() bool {
+	return config.Algorithm == "HS256" || config.Purpose == "local"
+}
+
+// Validate checks the config without resolving its keys, reporting every problem at once
+func (config MakerConfig) Validate() error {
+	return config.validate(nil)
+}
+
+func (config MakerConfig) validate(revocationStores map[string]RevocationStore) error {
+	config = config.withDefaults()
+
+	var problems []error
+	problem := func(field, format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	switch config.Type {
+	case "jwt":
+		switch config.Algorithm {
+		case "HS256", "EdDSA", "RS256", "PS256", "ES256", "ES384":
+		default:
+			problem("algorithm", "unsupported JWT algorithm %q, must be HS256, EdDSA, RS256, PS256, ES256 or ES384", config.Algorithm)
+		}
+		if config.Version != "" || config.Purpose != "" {
+			problem("version", "version and purpose only apply to PASETO")
+		}
+	case "paseto":
+		if config.Version != "v2" && config.Version != "v3" && config.Version != "v4" {
+			problem("version", "unsupported PASETO version %q, must be v2, v3 or v4", config.Version)
+		}
+		if config.Purpose != "local" && config.Purpose != "public" {
+			problem("purpose", "unsupported PASETO purpose %q, must be local or public", config.Purpose)
+		}
+		if config.Algorithm != "" {
+			problem("algorithm", "algorithm only applies to JWT")
+		}
+	case "":
+		problem("type", "required, must be jwt or paseto")
+	default:
+		problem("type", "unsupported token type %q, must be jwt or paseto", config.Type)
+	}
+
+	keys := map[string]KeyRef{"secret_key": config.SecretKey, "private_key": config.PrivateKey, "public_key": config.PublicKey}
+	for _, field := range []string{"secret_key", "private_key", "public_key"} {
+		if keys[field] == "" {
+			continue
+		}
+		if err := keys[field].validate(); err != nil {
+			problem(field, "%v", err)
+		}
+	}
+	if config.Type == "jwt" || config.Type == "paseto" {
+		if config.symmetric() {
+			if config.SecretKey == "" {
+				problem("secret_key", "required")
+			}
+			if config.PrivateKey != "" || config.PublicKey != "" {
+				problem("private_key", "a maker with a secret key takes no key pair")
+			}
+		} else {
+			if config.PrivateKey == "" && config.PublicKey == "" {
+				problem("private_key", "a private or public key is required")
+			}
+			if config.SecretKey != "" {
+				problem("secret_key", "a maker with a key pair takes no secret key")
+			}
+		}
+	}
+
+	if config.TTL <= 0 {
+		problem("ttl", "required, must be positive")
+	}
+	if config.Leeway < 0 {
+		problem("leeway", "must not be negative")
+	}
+
+	if config.Revocation != "" && config.Revocation != "memory" {
+		if _, ok := revocationStores[config.Revocation]; !ok {
+			problem("revocation", "unknown revocation store %q", config.Revocation)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidMakerConfig, errors.Join(problems...))
+	}
+	return nil
+}
+
+// ConfigOption configures how NewMakerFromConfig builds a maker
+type ConfigOption func(*configBuilder)
+
+type configBuilder struct {
+	revocationStores map[string]RevocationStore
+	makerOptions     []Option
+}
+
+// WithConfigRevocationStore makes store available to configs under name, e.g. a store shared by every replica.
+// Revocations are handed to the store with the config's leeway already added to their expiry, so the store must
+// keep each one until the expiry it is given
+func WithConfigRevocationStore(name string, store RevocationStore) ConfigOption {
+	return func(builder *configBuilder) {
+		builder.revocationStores[name] = store
+	}
+}
+
+// WithConfigMakerOptions passes options the config can't express, such as WithClock, on to the maker
+func WithConfigMakerOptions(opts ...Option) ConfigOption {
+	return func(builder *configBuilder) {
+		builder.makerOptions = append(builder.makerOptions, opts...)
+	}
+}
+
+// ConfiguredMaker is a Maker built from a MakerConfig. It creates and verifies tokens with the maker the
+// config describes, and IssueToken creates them with the configured TTL
+type ConfiguredMaker struct {
+	maker ContextMaker
+	inner Maker
+	ttl   time.Duration
+}
+
+// NewMakerFromConfig validates config, resolves its keys and builds the maker it describes
+func NewMakerFromConfig(config MakerConfig, opts ...ConfigOption) (*ConfiguredMaker, error) {
+	builder := configBuilder{revocationStores: make(map[string]RevocationStore)}
+	for _, opt := range opts {
+		opt(&builder)
+	}
+
+	if err := config.validate(builder.revocationStores); err != nil {
+		return nil, err
+	}
+	config = config.withDefaults()
+
+	makerOptions := []Option{WithLeeway(time.Duration(config.Leeway))}
+	if config.KeyID != "" {
+		makerOptions = append(makerOptions, WithKeyID(config.KeyID))
+	}
+	if config.Issuer != "" {
+		makerOptions = append(makerOptions, WithIssuer(config.Issuer))
+	}
+	if len(config.Audience) > 0 {
+		makerOptions = append(makerOptions, WithAudience(config.Audience...))
+	}
+	if config.ExpectedIssuer != "" {
+		makerOptions = append(makerOptions, WithExpectedIssuer(config.ExpectedIssuer))
+	}
+	if config.ExpectedAudience != "" {
+		makerOptions = append(makerOptions, WithExpectedAudience(config.ExpectedAudience))
+	}
+	makerOptions = append(makerOptions, builder.makerOptions...)
+
+	var maker Maker
+	var err error
+	if config.Type == "jwt" {
+		maker, err = config.buildJWT(makerOptions)
+	} else {
+		maker, err = config.buildPaseto(makerOptions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMakerConfig, err)
+	}
+
+	switch config.Revocation {
+	case "":
+	case "memory":
+		maker = NewRevocableMaker(maker, NewMemoryRevocationStore(makerOptions...))
+	default:
+		maker = NewRevocableMaker(maker, builder.revocationStores[config.Revocation])
+	}
+
+	return &ConfiguredMaker{maker: AsContextMaker(maker), inner: maker, ttl: time.Duration(config.TTL)}, nil
+}
+
+// Maker returns the configured maker, a *RevocableMaker when the config asks for revocation
+func (maker *ConfiguredMaker) Maker() Maker {
+	return maker.inner
+}
+
+// TTL returns the configured token lifetime
+func (maker *ConfiguredMaker) TTL() time.Duration {
+	return maker.ttl
+}
+
+// IssueToken creates a token for username valid for the configured TTL
+func (maker *ConfiguredMaker) IssueToken(ctx context.Context, username string) (string, *Payload, error) {
+	return maker.maker.CreateTokenContext(ctx, username, maker.ttl)
+}
+
+// CreateToken creates a token for username valid for duration
+func (maker *ConfiguredMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.maker.CreateToken(username, duration)
+}
+
+// VerifyToken verifies a token
+func (maker *ConfiguredMaker) VerifyToken(token string) (*Payload, error) {
+	return maker.maker.VerifyToken(token)
+}
+
+// CreateTokenContext creates a token for username valid for duration
+func (maker *ConfiguredMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return maker.maker.CreateTokenContext(ctx, username, duration)
+}
+
+// VerifyTokenContext verifies a token
+func (maker *ConfiguredMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return maker.maker.VerifyTokenContext(ctx, token)
+}
+
+// resolveKey resolves ref, an empty ref is an empty key
+func resolveKey(field string, ref KeyRef) (string, error) {
+	if ref == "" {
+		return "", nil
+	}
+	key, err := ref.Resolve()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", field, err)
+	}
+	return key, nil
+}
+
+func (config MakerConfig) buildJWT(opts []Option) (Maker, error) {
+	if config.Algorithm == "HS256" {
+		secret, err := resolveKey("secret_key", config.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewJWTMaker(secret, opts...)
+	}
+
+	privateText, err := resolveKey("private_key", config.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicText, err := resolveKey("public_key", config.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateKey crypto.Signer
+	if privateText != "" {
+		if privateKey, err = parsePrivateKey(config.Algorithm, privateText); err != nil {
+			return nil, fmt.Errorf("private_key: %w", err)
+		}
+	}
+	var publicKey crypto.PublicKey
+	if publicText != "" {
+		if publicKey, err = parsePublicKey(config.Algorithm, publicText); err != nil {
+			return nil, fmt.Errorf("public_key: %w", err)
+		}
+	} else {
+		publicKey = privateKey.Public()
+	}
+
+	switch config.Algorithm {
+	case "EdDSA":
+		edPublicKey, ok := publicKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public_key: EdDSA needs an Ed25519 key, not %T", publicKey)
+		}
+		if privateKey == nil {
+			return NewAsymJWTVerifier(edPublicKey, opts...)
+		}
+		edPrivateKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private_key: EdDSA needs an Ed25519 key, not %T", privateKey)
+		}
+		return NewAsymJWTMaker(edPrivateKey, edPublicKey, opts...)
+	case "RS256", "PS256":
+		rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public_key: %s needs an RSA key, not %T", config.Algorithm, publicKey)
+		}
+		if privateKey == nil {
+			return NewRSAJWTVerifier(config.Algorithm, rsaPublicKey, opts...)
+		}
+		rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private_key: %s needs an RSA key, not %T", config.Algorithm, privateKey)
+		}
+		return NewRSAJWTMaker(config.Algorithm, rsaPrivateKey, rsaPublicKey, opts...)
+	default:
+		ecdsaPublicKey, ok := publicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public_key: %s needs an ECDSA key, not %T", config.Algorithm, publicKey)
+		}
+		if curve := ecdsaPublicKey.Curve.Params().Name; (config.Algorithm == "ES256") != (curve == "P-256") {
+			return nil, fmt.Errorf("public_key: %s can't be used with a %s key", config.Algorithm, curve)
+		}
+		if privateKey == nil {
+			return NewECDSAJWTVerifier(ecdsaPublicKey, opts...)
+		}
+		ecdsaPrivateKey, ok := privateKey.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("private_key: %s needs an ECDSA key, not %T", config.Algorithm, privateKey)
+		}
+		return NewECDSAJWTMaker(ecdsaPrivateKey, ecdsaPublicKey, opts...)
+	}
+}
+
+// parsePrivateKey parses a PEM private key, or for EdDSA a hex encoded one
+func parsePrivateKey(algorithm, text string) (crypto.Signer, error) {
+	if algorithm == "EdDSA" && !strings.HasPrefix(text, "-----BEGIN") {
+		key, err := hex.DecodeString(text)
+		if err != nil || len(key) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("must be PEM or a hex encoded %d byte Ed25519 key", ed25519.PrivateKeySize)
+		}
+		return ed25519.PrivateKey(key), nil
+	}
+
+	return ParsePEMPrivateKey([]byte(text))
+}
+
+// parsePublicKey parses a PEM public key, or for EdDSA a hex encoded one
+func parsePublicKey(algorithm, text string) (crypto.PublicKey, error) {
+	if algorithm == "EdDSA" && !strings.HasPrefix(text, "-----BEGIN") {
+		key, err := hex.DecodeString(text)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("must be PEM or a hex encoded %d byte Ed25519 key", ed25519.PublicKeySize)
+		}
+		return ed25519.PublicKey(key), nil
+	}
+
+	return ParsePEMPublicKey([]byte(text))
+}
+
+func (config MakerConfig) buildPaseto(opts []Option) (Maker, error) {
+	version := int(config.Version[1] - '0')
+
+	if config.Purpose == "local" {
+		secret, err := resolveKey("secret_key", config.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		secretHex, err := pasetoKeyHex(secret, version, paserkLocal)
+		if err != nil {
+			return nil, fmt.Errorf("secret_key: %w", err)
+		}
+
+		switch version {
+		case 2:
+			return NewPasetoV2Local(secretHex, opts...)
+		case 3:
+			return NewPasetoV3Local(secretHex, opts...)
+		default:
+			return NewPasetoV4Local(secretHex, opts...)
+		}
+	}
+
+	privateText, err := resolveKey("private_key", config.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	publicText, err := resolveKey("public_key", config.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var privateHex, publicHex string
+	if privateText != "" {
+		if privateHex, err = pasetoKeyHex(privateText, version, paserkSecret); err != nil {
+			return nil, fmt.Errorf("private_key: %w", err)
+		}
+	}
+	if publicText != "" {
+		if publicHex, err = pasetoKeyHex(publicText, version, paserkPublic); err != nil {
+			return nil, fmt.Errorf("public_key: %w", err)
+		}
+	} else if publicHex, err = pasetoPublicKeyHex(version, privateHex); err != nil {
+		return nil, fmt.Errorf("private_key: %w", err)
+	}
+
+	switch {
+	case version == 2 && privateHex == "":
+		return NewPasetoV2PublicVerifier(publicHex, opts...)
+	case version == 2:
+		return NewPasetoV2Public(privateHex, publicHex, opts...)
+	case version == 3 && privateHex == "":
+		return NewPasetoV3PublicVerifier(publicHex, opts...)
+	case version == 3:
+		return NewPasetoV3Public(privateHex, publicHex, opts...)
+	case privateHex == "":
+		return NewPasetoV4PublicVerifier(publicHex, opts...)
+	default:
+		return NewPasetoV4Public(privateHex, publicHex, opts...)
+	}
+}
+
+// pasetoKeyHex returns a PASETO key given as hex or as a PASERK of the expected version and type as hex
+func pasetoKeyHex(text string, version int, keyType string) (string, error) {
+	if paserkHeader.MatchString(text) {
+		key, err := decodePASERK(text, version, keyType)
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(key), nil
+	}
+
+	if _, err := hex.DecodeString(text); err != nil {
+		return "", fmt.Errorf("must be hex or a k%d.%s PASERK", version, keyType)
+	}
+	return text, nil
+}
+
+// pasetoPublicKeyHex derives the public key of a hex encoded PASETO secret key
+func pasetoPublicKeyHex(version int, privateHex string) (string, error) {
+	switch version {
+	case 2:
+		key, err := paseto.NewV2AsymmetricSecretKeyFromHex(privateHex)
+		if err != nil {
+			return "", err
+		}
+		return key.Public().ExportHex(), nil
+	case 3:
+		key, err := paseto.NewV3AsymmetricSecretKeyFromHex(privateHex)
+		if err != nil {
+			return "", err
+		}
+		return key.Public().ExportHex(), nil
+	default:
+		key, err := paseto.NewV4AsymmetricSecretKeyFromHex(privateHex)
+		if err != nil {
+			return "", err
+		}
+		return key.Public().ExportHex(), nil
+	}
+}
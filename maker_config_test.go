@@ -0,0 +1,239 @@
+package token
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestMakerConfig(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("YAML", func(t *testing.T) {
+		dir := t.TempDir()
+		secret := paseto.NewV4AsymmetricSecretKey()
+		keyFile := filepath.Join(dir, "private")
+		require.NoError(t, os.WriteFile(keyFile, []byte(secret.ExportHex()+"\n"), 0o600))
+
+		configFile := filepath.Join(dir, "token.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte(`
+type: paseto
+version: v4
+purpose: public
+private_key: file:`+keyFile+`
+issuer: auth.example.com
+audience: [api.example.com]
+expected_audience: api.example.com
+ttl: 15m
+leeway: 30s
+revocation: memory
+`), 0o600))
+
+		config, err := LoadMakerConfig(configFile)
+		require.NoError(t, err)
+		require.Equal(t, Duration(15*time.Minute), config.TTL)
+
+		maker, err := NewMakerFromConfig(config)
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Minute, maker.TTL())
+
+		token, payload, err := maker.IssueToken(ctx, "test_user")
+		require.NoError(t, err)
+		require.WithinDuration(t, time.Now().Add(15*time.Minute), payload.ExpiredAt, time.Second)
+		require.Equal(t, "auth.example.com", payload.Issuer)
+		require.True(t, strings.HasPrefix(token, "v4.public."))
+
+		// the public key was derived from the private one
+		verifier, err := NewPasetoV4PublicVerifier(secret.Public().ExportHex())
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(token)
+		require.NoError(t, err)
+
+		revocable, ok := maker.Maker().(*RevocableMaker)
+		require.True(t, ok)
+		require.NoError(t, revocable.RevokeContext(ctx, payload))
+		_, err = maker.VerifyToken(token)
+		require.ErrorIs(t, err, ErrRevokedToken)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		config, err := ParseMakerConfig([]byte(`{"type": "jwt", "secret_key": "value:` + randomString(32) + `", "ttl": "1h"}`))
+		require.NoError(t, err)
+
+		maker, err := NewMakerFromConfig(config)
+		require.NoError(t, err)
+		_, ok := maker.Maker().(*JWTMaker)
+		require.True(t, ok)
+
+		token, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("TOKEN_TYPE", "paseto")
+		t.Setenv("TOKEN_VERSION", "v2")
+		t.Setenv("TOKEN_SECRET_KEY", "env:TOKEN_TEST_SECRET")
+		t.Setenv("TOKEN_TEST_SECRET", paseto.NewV2SymmetricKey().ExportHex())
+		t.Setenv("TOKEN_AUDIENCE", "a.example.com, b.example.com")
+		t.Setenv("TOKEN_TTL", "10m")
+
+		config, err := MakerConfigFromEnv("TOKEN")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.example.com", "b.example.com"}, config.Audience)
+
+		maker, err := NewMakerFromConfig(config)
+		require.NoError(t, err)
+		_, ok := maker.Maker().(*PasetoV2Local)
+		require.True(t, ok)
+
+		t.Setenv("TOKEN_LEEWAY", "soon")
+		_, err = MakerConfigFromEnv("TOKEN")
+		require.ErrorIs(t, err, ErrInvalidMakerConfig)
+		require.ErrorContains(t, err, "TOKEN_LEEWAY")
+	})
+
+	t.Run("Makers", func(t *testing.T) {
+		edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+		v3Secret := paseto.NewV3AsymmetricSecretKey()
+		v4Local, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+
+		configs := map[string]struct {
+			config MakerConfig
+			maker  Maker
+		}{
+			"EdDSA":               {MakerConfig{Type: "jwt", Algorithm: "EdDSA", PrivateKey: KeyRef("value:" + hex.EncodeToString(edPrivateKey))}, &AsymJWTMaker{}},
+			"PS256":               {MakerConfig{Type: "jwt", Algorithm: "PS256", PrivateKey: KeyRef("value:" + pemPrivateKey(t, testRSAKey()))}, &RSAJWTMaker{}},
+			"ES384":               {MakerConfig{Type: "jwt", Algorithm: "ES384", PrivateKey: KeyRef("value:" + pemPrivateKey(t, p384Key))}, &ECDSAJWTMaker{}},
+			"PasetoV3Public":      {MakerConfig{Type: "paseto", Version: "v3", Purpose: "public", PrivateKey: KeyRef("value:" + v3Secret.ExportHex())}, &PasetoV3Public{}},
+			"PasetoV4LocalPASERK": {MakerConfig{Type: "paseto", SecretKey: KeyRef("value:" + v4Local.PASERK())}, &PasetoV4Local{}},
+		}
+
+		for name, test := range configs {
+			t.Run(name, func(t *testing.T) {
+				test.config.TTL = Duration(time.Minute)
+				maker, err := NewMakerFromConfig(test.config)
+				require.NoError(t, err)
+				require.IsType(t, test.maker, maker.Maker())
+
+				token, _, err := maker.IssueToken(ctx, "test_user")
+				require.NoError(t, err)
+				_, err = maker.VerifyTokenContext(ctx, token)
+				require.NoError(t, err)
+			})
+		}
+
+		t.Run("Verifier", func(t *testing.T) {
+			maker, err := NewMakerFromConfig(MakerConfig{
+				Type:      "jwt",
+				Algorithm: "EdDSA",
+				PublicKey: KeyRef("value:" + hex.EncodeToString(edPublicKey)),
+				TTL:       Duration(time.Minute),
+			})
+			require.NoError(t, err)
+
+			_, _, err = maker.IssueToken(ctx, "test_user")
+			require.ErrorIs(t, err, ErrVerificationOnly)
+		})
+	})
+
+	t.Run("RevocationStore", func(t *testing.T) {
+		store := &contextRecordingStore{RevocationStore: NewMemoryRevocationStore()}
+		maker, err := NewMakerFromConfig(MakerConfig{
+			Type:       "paseto",
+			SecretKey:  KeyRef("value:" + paseto.NewV4SymmetricKey().ExportHex()),
+			TTL:        Duration(time.Minute),
+			Revocation: "shared",
+		}, WithConfigRevocationStore("shared", store), WithConfigMakerOptions(WithLeeway(time.Second)))
+		require.NoError(t, err)
+
+		token, _, err := maker.IssueToken(ctx, "test_user")
+		require.NoError(t, err)
+		_, err = maker.VerifyTokenContext(ctx, token)
+		require.NoError(t, err)
+		require.NotNil(t, store.ctx)
+	})
+
+	t.Run("RevocationLeeway", func(t *testing.T) {
+		maker, err := NewMakerFromConfig(MakerConfig{
+			Type:       "paseto",
+			SecretKey:  KeyRef("value:" + paseto.NewV4SymmetricKey().ExportHex()),
+			TTL:        Duration(50 * time.Millisecond),
+			Leeway:     Duration(5 * time.Second),
+			Revocation: "memory",
+		})
+		require.NoError(t, err)
+
+		token, payload, err := maker.IssueToken(ctx, "test_user")
+		require.NoError(t, err)
+		require.NoError(t, maker.Maker().(*RevocableMaker).RevokeContext(ctx, payload))
+
+		// expired, but still within the leeway
+		time.Sleep(100 * time.Millisecond)
+		_, err = maker.VerifyTokenContext(ctx, token)
+		require.ErrorIs(t, err, ErrRevokedToken)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		// every problem is reported at once
+		err := MakerConfig{Type: "paseto", Version: "v5", Purpose: "public", SecretKey: "abc", Leeway: -1, Revocation: "redis"}.Validate()
+		require.ErrorIs(t, err, ErrInvalidMakerConfig)
+		for _, field := range []string{"version", "secret_key", "private_key", "ttl", "leeway", "revocation"} {
+			require.ErrorContains(t, err, field+":")
+		}
+
+		invalid := map[string]MakerConfig{
+			"NoType":         {TTL: Duration(time.Minute)},
+			"UnknownType":    {Type: "saml", TTL: Duration(time.Minute)},
+			"UnknownJWTAlg":  {Type: "jwt", Algorithm: "none", SecretKey: "value:abc", TTL: Duration(time.Minute)},
+			"JWTWithVersion": {Type: "jwt", Version: "v4", SecretKey: "value:abc", TTL: Duration(time.Minute)},
+			"NoKey":          {Type: "paseto", TTL: Duration(time.Minute)},
+			"KeyPairForHMAC": {Type: "jwt", SecretKey: "value:abc", PrivateKey: "value:abc", TTL: Duration(time.Minute)},
+			"EmptyKeyRef":    {Type: "jwt", SecretKey: "env:", TTL: Duration(time.Minute)},
+			"UnsetEnv":       {Type: "jwt", SecretKey: "env:TOKEN_TEST_UNSET", TTL: Duration(time.Minute)},
+			"MissingFile":    {Type: "paseto", SecretKey: KeyRef("file:" + filepath.Join(t.TempDir(), "missing")), TTL: Duration(time.Minute)},
+			"NotHex":         {Type: "paseto", SecretKey: "value:not hex", TTL: Duration(time.Minute)},
+			"WrongPASERK":    {Type: "paseto", Version: "v2", SecretKey: "value:k4.local.AAAA", TTL: Duration(time.Minute)},
+			"WrongKeyType":   {Type: "jwt", Algorithm: "ES256", PrivateKey: KeyRef("value:" + pemPrivateKey(t, testRSAKey())), TTL: Duration(time.Minute)},
+		}
+		for name, config := range invalid {
+			t.Run(name, func(t *testing.T) {
+				_, err := NewMakerFromConfig(config)
+				require.ErrorIs(t, err, ErrInvalidMakerConfig)
+			})
+		}
+
+		for _, document := range []string{
+			"type: jwt\nsecret_key: value:abc\nttl: 15m\ntll: 1h\n",
+			"type: jwt\nsecret_key: value:abc\nttl: 900\n",
+			"type: [jwt",
+		} {
+			_, err := ParseMakerConfig([]byte(document))
+			require.ErrorIs(t, err, ErrInvalidMakerConfig, document)
+		}
+	})
+}
+
+func pemPrivateKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
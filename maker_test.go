@@ -0,0 +1,97 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"sync"
+	"testing"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+// testRSAKey is generated once, RSA key generation is too slow to repeat for every test
+var testRSAKey = sync.OnceValue(func() *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return key
+})
+
+// newTestMakers builds one maker of every supported kind with the given options, keyed by a readable name
+func newTestMakers(t *testing.T, opts ...Option) map[string]Maker {
+	t.Helper()
+
+	jwtMaker, err := NewJWTMaker(randomString(32), opts...)
+	require.NoError(t, err)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	asymJWTMaker, err := NewAsymJWTMaker(privateKey, publicKey, opts...)
+	require.NoError(t, err)
+
+	rs256Maker, err := NewRSAJWTMaker("RS256", testRSAKey(), &testRSAKey().PublicKey, opts...)
+	require.NoError(t, err)
+	ps256Maker, err := NewRSAJWTMaker("PS256", testRSAKey(), &testRSAKey().PublicKey, opts...)
+	require.NoError(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	es256Maker, err := NewECDSAJWTMaker(p256Key, &p256Key.PublicKey, opts...)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	es384Maker, err := NewECDSAJWTMaker(p384Key, &p384Key.PublicKey, opts...)
+	require.NoError(t, err)
+
+	jweKey := make([]byte, 32)
+	_, err = rand.Read(jweKey)
+	require.NoError(t, err)
+	jweDirect, err := NewJWEDirectMaker(jweKey, opts...)
+	require.NoError(t, err)
+	jweECDH, err := NewJWEECDHMaker(p256Key, &p256Key.PublicKey, append([]Option{WithNestedSigner(es256Maker)}, opts...)...)
+	require.NoError(t, err)
+
+	v2Local, err := NewPasetoV2Local(paseto.NewV2SymmetricKey().ExportHex(), opts...)
+	require.NoError(t, err)
+
+	v2Secret := paseto.NewV2AsymmetricSecretKey()
+	v2Public, err := NewPasetoV2Public(v2Secret.ExportHex(), v2Secret.Public().ExportHex(), opts...)
+	require.NoError(t, err)
+
+	v3Local, err := NewPasetoV3Local(paseto.NewV3SymmetricKey().ExportHex(), opts...)
+	require.NoError(t, err)
+
+	v3Secret := paseto.NewV3AsymmetricSecretKey()
+	v3Public, err := NewPasetoV3Public(v3Secret.ExportHex(), v3Secret.Public().ExportHex(), opts...)
+	require.NoError(t, err)
+
+	v4Local, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex(), opts...)
+	require.NoError(t, err)
+
+	v4Secret := paseto.NewV4AsymmetricSecretKey()
+	v4Public, err := NewPasetoV4Public(v4Secret.ExportHex(), v4Secret.Public().ExportHex(), opts...)
+	require.NoError(t, err)
+
+	return map[string]Maker{
+		"JWTMaker":       jwtMaker,
+		"AsymJWTMaker":   asymJWTMaker,
+		"RS256JWTMaker":  rs256Maker,
+		"PS256JWTMaker":  ps256Maker,
+		"ES256JWTMaker":  es256Maker,
+		"ES384JWTMaker":  es384Maker,
+		"JWEDirect":      jweDirect,
+		"JWEECDH":        jweECDH,
+		"PasetoV2Local":  v2Local,
+		"PasetoV2Public": v2Public,
+		"PasetoV3Local":  v3Local,
+		"PasetoV3Public": v3Public,
+		"PasetoV4Local":  v4Local,
+		"PasetoV4Public": v4Public,
+	}
+}
@@ -0,0 +1,248 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrMissingToken is returned when a request doesn't carry a token
+var ErrMissingToken = errors.New("token is missing")
+
+// TokenExtractor reads the raw token from a request, returning "" when the request has none
+type TokenExtractor func(r *http.Request) string
+
+// FromAuthorizationHeader extracts a bearer token from the Authorization header (RFC 6750 section 2.1)
+func FromAuthorizationHeader() TokenExtractor {
+	return func(r *http.Request) string {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	}
+}
+
+// FromCookie extracts the token from the named cookie
+func FromCookie(name string) TokenExtractor {
+	return func(r *http.Request) string {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	}
+}
+
+// FromQuery extracts the token from the named query parameter
+func FromQuery(param string) TokenExtractor {
+	return func(r *http.Request) string {
+		return r.URL.Query().Get(param)
+	}
+}
+
+// FirstOf tries each extractor in turn and returns the first token found
+func FirstOf(extractors ...TokenExtractor) TokenExtractor {
+	return func(r *http.Request) string {
+		for _, extract := range extractors {
+			if token := extract(r); token != "" {
+				return token
+			}
+		}
+		return ""
+	}
+}
+
+// ErrorHandler writes the response for a request that failed authentication
+type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// MiddlewareOption configures the authentication middleware
+type MiddlewareOption func(*middleware)
+
+type middleware struct {
+	maker       ContextMaker
+	extract     TokenExtractor
+	optional    func(r *http.Request) bool
+	handleError ErrorHandler
+	realm       string
+
+	// scope is advertised in the insufficient_scope challenge of RequireScopes
+	scope string
+}
+
+// WithTokenExtractor sets where the middleware looks for the token, the Authorization header by default
+func WithTokenExtractor(extractor TokenExtractor) MiddlewareOption {
+	return func(m *middleware) {
+		m.extract = extractor
+	}
+}
+
+// WithOptionalRoutes lets the requests matched by match through without a token. Tokens they do carry
+// are still verified, and rejected if invalid
+func WithOptionalRoutes(match func(r *http.Request) bool) MiddlewareOption {
+	return func(m *middleware) {
+		m.optional = match
+	}
+}
+
+// WithErrorHandler replaces the default RFC 6750 error responses
+func WithErrorHandler(handler ErrorHandler) MiddlewareOption {
+	return func(m *middleware) {
+		m.handleError = handler
+	}
+}
+
+// WithRealm sets the realm advertised in the WWW-Authenticate challenge
+func WithRealm(realm string) MiddlewareOption {
+	return func(m *middleware) {
+		m.realm = realm
+	}
+}
+
+// Middleware authenticates requests with maker. The verified payload is stored in the request
+// context, see PayloadFromContext. Requests without a valid token are rejected with a 401 and a
+// WWW-Authenticate challenge as described in RFC 6750, those whose token couldn't be verified, e.g. because
+// a revocation store is down, get a 503. Tokens are verified under the request's context, see ContextMaker
+func Middleware(maker Maker, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := newMiddleware(maker, opts)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := m.extract(r)
+			if token == "" {
+				if m.optional(r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				m.handleError(w, r, ErrMissingToken)
+				return
+			}
+
+			payload, err := m.maker.VerifyTokenContext(r.Context(), token)
+			if err != nil {
+				m.handleError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), payload)))
+		})
+	}
+}
+
+// Authorize returns middleware that only lets a request through when check accepts the payload Middleware
+// stored in its context, so it must be installed after Middleware. Requests check rejects get a 403 with an
+// insufficient_scope challenge (RFC 6750 section 3.1), requests without a payload a 401.
+// WithErrorHandler and WithRealm apply, the handler receives an error wrapping ErrInsufficientScope
+func Authorize(check func(payload *Payload) error, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return newMiddleware(nil, opts).authorize(check)
+}
+
+// RequireScopes returns middleware that only lets through requests whose token grants every one of scopes
+// (see Payload.RequireScopes). It must be installed after Middleware, WithErrorHandler and WithRealm apply as
+// they do to Authorize
+func RequireScopes(scopes []string, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := newMiddleware(nil, opts)
+	m.scope = strings.Join(scopes, " ")
+	return m.authorize(func(payload *Payload) error {
+		return payload.RequireScopes(scopes...)
+	})
+}
+
+// RequireAnyRole returns middleware that only lets through requests whose token has at least one of roles
+// (see Payload.RequireAnyRole). It must be installed after Middleware, WithErrorHandler and WithRealm apply as
+// they do to Authorize
+func RequireAnyRole(roles []string, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return newMiddleware(nil, opts).authorize(func(payload *Payload) error {
+		return payload.RequireAnyRole(roles...)
+	})
+}
+
+func newMiddleware(maker Maker, opts []MiddlewareOption) *middleware {
+	m := &middleware{
+		extract:  FromAuthorizationHeader(),
+		optional: func(*http.Request) bool { return false },
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.handleError == nil {
+		m.handleError = m.writeChallenge
+	}
+	if maker != nil {
+		m.maker = AsContextMaker(maker)
+	}
+	return m
+}
+
+func (m *middleware) authorize(check func(payload *Payload) error) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			payload, ok := PayloadFromContext(r.Context())
+			if !ok {
+				m.handleError(w, r, ErrMissingToken)
+				return
+			}
+
+			if err := check(payload); err != nil {
+				if !errors.Is(err, ErrInsufficientScope) {
+					err = fmt.Errorf("%w: %w", ErrInsufficientScope, err)
+				}
+				m.handleError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// challengeDescription says why a token was rejected, without revealing anything about the maker's keys
+func challengeDescription(err error) string {
+	switch {
+	case errors.Is(err, ErrExpiredToken):
+		return "the access token has expired"
+	case errors.Is(err, ErrTokenNotYetValid):
+		return "the access token is not valid yet"
+	case errors.Is(err, ErrRevokedToken):
+		return "the access token has been revoked"
+	default:
+		return "the access token is invalid"
+	}
+}
+
+// writeChallenge is the default ErrorHandler, answering with a 401, or a 403 for insufficient scope, and an RFC 6750
+// challenge. Tokens that couldn't be verified get a 503 without a challenge, the client isn't at fault
+func (m *middleware) writeChallenge(w http.ResponseWriter, _ *http.Request, err error) {
+	if !errors.Is(err, ErrMissingToken) && !errors.Is(err, ErrInsufficientScope) && !IsTokenRejected(err) {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+
+	var params []string
+	if m.realm != "" {
+		params = append(params, fmt.Sprintf("realm=%q", m.realm))
+	}
+
+	status := http.StatusUnauthorized
+	switch {
+	case errors.Is(err, ErrMissingToken):
+		// a request without any token gets a bare challenge (RFC 6750 section 3.1)
+	case errors.Is(err, ErrInsufficientScope):
+		status = http.StatusForbidden
+		params = append(params, `error="insufficient_scope"`, `error_description="the access token lacks the required scope"`)
+		if m.scope != "" {
+			params = append(params, fmt.Sprintf("scope=%q", m.scope))
+		}
+	default:
+		params = append(params, `error="invalid_token"`, fmt.Sprintf("error_description=%q", challengeDescription(err)))
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, http.StatusText(status), status)
+}
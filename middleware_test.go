@@ -0,0 +1,262 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	maker, err := NewJWTMaker(randomString(32))
+	require.NoError(t, err)
+
+	validToken, _, err := maker.CreateToken("alice", time.Minute)
+	require.NoError(t, err)
+	expiredToken, _, err := maker.CreateToken("alice", -time.Minute)
+	require.NoError(t, err)
+
+	protected := func(opts ...MiddlewareOption) http.Handler {
+		return Middleware(maker, opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if payload, ok := PayloadFromContext(r.Context()); ok {
+				_, _ = w.Write([]byte(payload.Username))
+				return
+			}
+			_, _ = w.Write([]byte("anonymous"))
+		}))
+	}
+
+	serve := func(handler http.Handler, request *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("AuthorizationHeader", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+validToken)
+
+		recorder := serve(protected(), request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "alice", recorder.Body.String())
+	})
+
+	t.Run("MissingToken", func(t *testing.T) {
+		recorder := serve(protected(WithRealm("api")), httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Equal(t, `Bearer realm="api"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+expiredToken)
+
+		recorder := serve(protected(), request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Equal(t, `Bearer error="invalid_token", error_description="the access token has expired"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("InvalidToken", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer not-a-token")
+
+		recorder := serve(protected(), request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Equal(t, `Bearer error="invalid_token", error_description="the access token is invalid"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("NotYetValidToken", func(t *testing.T) {
+		payload, err := NewPayload("alice", time.Hour)
+		require.NoError(t, err)
+		payload.NotBefore = time.Now().Add(time.Minute)
+		notYetValidToken, err := maker.(ClaimsMaker).CreateTokenFromPayload(payload)
+		require.NoError(t, err)
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+notYetValidToken)
+
+		recorder := serve(protected(), request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Equal(t, `Bearer error="invalid_token", error_description="the access token is not valid yet"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("VerificationFailure", func(t *testing.T) {
+		revocable := NewRevocableMaker(maker, failingRevocationStore{})
+		handler := Middleware(revocable)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			t.Fatal("handler called")
+		}))
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+validToken)
+
+		recorder := serve(handler, request)
+		require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		require.Empty(t, recorder.Header().Get("WWW-Authenticate"))
+
+		// the token is still checked first
+		request.Header.Set("Authorization", "Bearer "+expiredToken)
+		recorder = serve(handler, request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("CookieAndQuery", func(t *testing.T) {
+		handler := protected(WithTokenExtractor(FirstOf(FromCookie("session"), FromQuery("access_token"))))
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.AddCookie(&http.Cookie{Name: "session", Value: validToken})
+		recorder := serve(handler, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = serve(handler, httptest.NewRequest(http.MethodGet, "/?access_token="+validToken, nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		// The Authorization header isn't consulted anymore
+		request = httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+validToken)
+		recorder = serve(handler, request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("OptionalRoutes", func(t *testing.T) {
+		handler := protected(WithOptionalRoutes(func(r *http.Request) bool {
+			return r.URL.Path == "/public"
+		}))
+
+		recorder := serve(handler, httptest.NewRequest(http.MethodGet, "/public", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Equal(t, "anonymous", recorder.Body.String())
+
+		request := httptest.NewRequest(http.MethodGet, "/public", nil)
+		request.Header.Set("Authorization", "Bearer "+validToken)
+		recorder = serve(handler, request)
+		require.Equal(t, "alice", recorder.Body.String())
+
+		// An invalid token is rejected even on optional routes
+		request = httptest.NewRequest(http.MethodGet, "/public", nil)
+		request.Header.Set("Authorization", "Bearer "+expiredToken)
+		recorder = serve(handler, request)
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+		recorder = serve(handler, httptest.NewRequest(http.MethodGet, "/private", nil))
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("CustomErrorHandler", func(t *testing.T) {
+		var handled error
+		handler := protected(WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusTeapot)
+		}))
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Authorization", "Bearer "+expiredToken)
+		recorder := serve(handler, request)
+		require.Equal(t, http.StatusTeapot, recorder.Code)
+		require.ErrorIs(t, handled, ErrExpiredToken)
+
+		serve(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.ErrorIs(t, handled, ErrMissingToken)
+	})
+}
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	maker, err := NewJWTMaker(randomString(32))
+	require.NoError(t, err)
+
+	payload, err := NewPayload("alice", time.Minute)
+	require.NoError(t, err)
+	payload.Scopes = Scopes{"orders:*"}
+	payload.Roles = []string{"support"}
+	token, err := maker.(ClaimsMaker).CreateTokenFromPayload(payload)
+	require.NoError(t, err)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	serve := func(authorize func(http.Handler) http.Handler, token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		Middleware(maker, WithOptionalRoutes(func(*http.Request) bool { return true }))(authorize(ok)).ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("RequireScopes", func(t *testing.T) {
+		recorder := serve(RequireScopes([]string{"orders:read", "orders:items:write"}), token)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = serve(RequireScopes([]string{"orders:read", "invoices:read"}), token)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, `Bearer error="insufficient_scope", error_description="the access token lacks the required scope", scope="orders:read invoices:read"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("RequireAnyRole", func(t *testing.T) {
+		recorder := serve(RequireAnyRole([]string{"admin", "support"}), token)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = serve(RequireAnyRole([]string{"admin"}), token)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, `Bearer error="insufficient_scope", error_description="the access token lacks the required scope"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("MissingPayload", func(t *testing.T) {
+		recorder := serve(RequireScopes([]string{"orders:read"}), "")
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Authorize", func(t *testing.T) {
+		var handled error
+		authorize := Authorize(func(payload *Payload) error {
+			if payload.Username != "bob" {
+				return errors.New("only bob may do this")
+			}
+			return nil
+		}, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusForbidden)
+		}))
+
+		recorder := serve(authorize, token)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.ErrorIs(t, handled, ErrInsufficientScope)
+		require.ErrorContains(t, handled, "only bob may do this")
+
+		recorder = serve(Authorize(func(*Payload) error { return errors.New("denied") }, WithRealm("api")), token)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, `Bearer realm="api", error="insufficient_scope", error_description="the access token lacks the required scope"`, recorder.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		recorder := serve(RequireScopes([]string{"invoices:read"}, WithRealm("api")), token)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Equal(t, `Bearer realm="api", error="insufficient_scope", error_description="the access token lacks the required scope", scope="invoices:read"`, recorder.Header().Get("WWW-Authenticate"))
+
+		var handled error
+		recorder = serve(RequireAnyRole([]string{"admin"}, WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
+			handled = err
+			w.WriteHeader(http.StatusTeapot)
+		})), token)
+		require.Equal(t, http.StatusTeapot, recorder.Code)
+		require.ErrorIs(t, handled, ErrInsufficientScope)
+	})
+}
+
+// failingRevocationStore is a RevocationStore whose backend is down
+type failingRevocationStore struct{}
+
+func (failingRevocationStore) Revoke(context.Context, uuid.UUID, time.Time) error {
+	return errors.New("connection refused")
+}
+
+func (failingRevocationStore) IsRevoked(context.Context, uuid.UUID) (bool, error) {
+	return false, errors.New("connection refused")
+}
@@ -0,0 +1,277 @@
+package token
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FormatMaker is a Maker that can tell which token format it creates, so a MultiMaker can route tokens to it.
+// Formats are the PASETO header without its trailing dot ("v4.local", "v3.public"), "jwt/<alg>" for JWTs
+// ("jwt/HS256", "jwt/EdDSA"), "jwe/<alg>/<enc>" for JWEs ("jwe/dir/A256GCM") and "apikey/<prefix>" for API keys
+type FormatMaker interface {
+	Maker
+
+	// TokenFormat The format of the tokens the maker creates
+	TokenFormat() string
+}
+
+// MultiMaker is a Maker that verifies tokens in several formats, e.g. while migrating from JWTs to PASETO.
+// New tokens are created by the primary maker. Tokens are verified by the one maker registered for their
+// format, read from the PASETO header or the JWT/JWE "alg" header: keys are never tried one after the other
+type MultiMaker struct {
+	mu            sync.RWMutex
+	primaryFormat string
+	makers        map[string]Maker
+}
+
+// NewMultiMaker creates a MultiMaker creating tokens with primary, that also accepts the tokens of the other makers.
+// Every maker must be a FormatMaker, others can be added later with RegisterFormat
+func NewMultiMaker(primary Maker, others ...Maker) (*MultiMaker, error) {
+	if primary == nil {
+		return nil, fmt.Errorf("a MultiMaker needs a primary maker")
+	}
+	multi := &MultiMaker{makers: make(map[string]Maker)}
+
+	for i, maker := range append([]Maker{primary}, others...) {
+		format, err := multi.register(maker)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			multi.primaryFormat = format
+		}
+	}
+
+	return multi, nil
+}
+
+// Register adds a maker that verifies tokens in its format
+func (multi *MultiMaker) Register(maker Maker) error {
+	_, err := multi.register(maker)
+	return err
+}
+
+// register adds a FormatMaker and returns the format it was registered for
+func (multi *MultiMaker) register(maker Maker) (string, error) {
+	formatMaker, ok := maker.(FormatMaker)
+	if !ok {
+		return "", fmt.Errorf("maker %T doesn't know its token format, register it with RegisterFormat", maker)
+	}
+	format := formatMaker.TokenFormat()
+	return format, multi.RegisterFormat(format, maker)
+}
+
+// RegisterFormat adds a maker that verifies tokens in the given format. It is meant for makers that
+// don't know their format themselves, such as a Keyring, a RevocableMaker or a JWKSVerifier
+func (multi *MultiMaker) RegisterFormat(format string, maker Maker) error {
+	multi.mu.Lock()
+	defer multi.mu.Unlock()
+
+	if _, ok := multi.makers[format]; ok {
+		return fmt.Errorf("a maker is already registered for %q tokens", format)
+	}
+	multi.makers[format] = maker
+	return nil
+}
+
+// Unregister stops accepting tokens in the given format
+func (multi *MultiMaker) Unregister(format string) error {
+	multi.mu.Lock()
+	defer multi.mu.Unlock()
+
+	if format == multi.primaryFormat {
+		return fmt.Errorf("cannot unregister the primary format %q", format)
+	}
+	delete(multi.makers, format)
+	return nil
+}
+
+// PrimaryFormat returns the format new tokens are created in
+func (multi *MultiMaker) PrimaryFormat() string {
+	multi.mu.RLock()
+	defer multi.mu.RUnlock()
+
+	return multi.primaryFormat
+}
+
+// TokenFormat returns the format new tokens are created in
+func (multi *MultiMaker) TokenFormat() string {
+	return multi.PrimaryFormat()
+}
+
+// Formats returns the sorted formats of the tokens the MultiMaker accepts
+func (multi *MultiMaker) Formats() []string {
+	multi.mu.RLock()
+	defer multi.mu.RUnlock()
+
+	formats := make([]string, 0, len(multi.makers))
+	for format := range multi.makers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func (multi *MultiMaker) primary() Maker {
+	multi.mu.RLock()
+	defer multi.mu.RUnlock()
+
+	return multi.makers[multi.primaryFormat]
+}
+
+// makerOptions returns the options of the primary maker, see optionsOf
+func (multi *MultiMaker) makerOptions() options {
+	return optionsOf(multi.primary())
+}
+
+// CreateToken creates a token with the primary maker
+func (multi *MultiMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return multi.primary().CreateToken(username, duration)
+}
+
+// CreateTokenContext creates a token with the primary maker
+func (multi *MultiMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return AsContextMaker(multi.primary()).CreateTokenContext(ctx, username, duration)
+}
+
+// CreateTokenWithClaims creates a token carrying custom claims with the primary maker
+func (multi *MultiMaker) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	maker, err := asClaimsMaker(multi.primary())
+	if err != nil {
+		return "", nil, err
+	}
+	return maker.CreateTokenWithClaims(username, duration, claims)
+}
+
+// CreateTokenFromPayload creates a token for a payload built by the caller with the primary maker
+func (multi *MultiMaker) CreateTokenFromPayload(payload *Payload) (string, error) {
+	maker, err := asClaimsMaker(multi.primary())
+	if err != nil {
+		return "", err
+	}
+	return maker.CreateTokenFromPayload(payload)
+}
+
+// VerifyToken verifies the token with the maker registered for its format
+func (multi *MultiMaker) VerifyToken(token string) (*Payload, error) {
+	return multi.VerifyTokenContext(context.Background(), token)
+}
+
+// VerifyTokenContext verifies the token with the maker registered for its format
+func (multi *MultiMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	format, err := DetectTokenFormat(token)
+	if err != nil {
+		return nil, err
+	}
+
+	multi.mu.RLock()
+	maker, ok := multi.makers[format]
+	multi.mu.RUnlock()
+
+	if !ok {
+		return nil, newVerificationError(ReasonWrongAlgorithm, fmt.Errorf("no maker accepts %q tokens", format))
+	}
+	return AsContextMaker(maker).VerifyTokenContext(ctx, token)
+}
+
+// DetectTokenFormat reads the format of a token (see FormatMaker) without verifying it
+func DetectTokenFormat(token string) (string, error) {
+	if header := pasetoHeader.FindString(token); header != "" {
+		return strings.TrimSuffix(header, "."), nil
+	}
+	if match := apiKeyPattern.FindStringSubmatch(token); match != nil {
+		return "apikey/" + match[1], nil
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 && len(parts) != 5 {
+		return "", newVerificationError(ReasonMalformed, nil)
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", newVerificationError(ReasonMalformed, err)
+	}
+
+	var header struct {
+		Algorithm  string `json:"alg"`
+		Encryption string `json:"enc"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil || header.Algorithm == "" {
+		return "", newVerificationError(ReasonMalformed, err)
+	}
+
+	if len(parts) == 5 {
+		if header.Encryption == "" {
+			return "", newVerificationError(ReasonMalformed, nil)
+		}
+		return "jwe/" + header.Algorithm + "/" + header.Encryption, nil
+	}
+	return "jwt/" + header.Algorithm, nil
+}
+
+// TokenFormat returns "jwt/HS256"
+func (maker *JWTMaker) TokenFormat() string {
+	return "jwt/HS256"
+}
+
+// TokenFormat returns "jwt/EdDSA"
+func (maker *AsymJWTMaker) TokenFormat() string {
+	return "jwt/EdDSA"
+}
+
+// TokenFormat returns "jwt/RS256" or "jwt/PS256"
+func (maker *RSAJWTMaker) TokenFormat() string {
+	return "jwt/" + maker.method.Alg()
+}
+
+// TokenFormat returns "jwt/ES256" or "jwt/ES384"
+func (maker *ECDSAJWTMaker) TokenFormat() string {
+	return "jwt/" + maker.method.Alg()
+}
+
+// TokenFormat returns "jwe/dir/A256GCM" or "jwe/ECDH-ES+A256KW/A256GCM"
+func (maker *JWEMaker) TokenFormat() string {
+	return "jwe/" + string(maker.keyAlgorithm) + "/" + string(jweContentEncryption)
+}
+
+// TokenFormat returns "v2.local"
+func (maker *PasetoV2Local) TokenFormat() string {
+	return "v2.local"
+}
+
+// TokenFormat returns "v2.public"
+func (maker *PasetoV2Public) TokenFormat() string {
+	return "v2.public"
+}
+
+// TokenFormat returns "v3.local"
+func (maker *PasetoV3Local) TokenFormat() string {
+	return "v3.local"
+}
+
+// TokenFormat returns "v3.public"
+func (maker *PasetoV3Public) TokenFormat() string {
+	return "v3.public"
+}
+
+// TokenFormat returns "v4.local"
+func (maker *PasetoV4Local) TokenFormat() string {
+	return "v4.local"
+}
+
+// TokenFormat returns "v4.public"
+func (maker *PasetoV4Public) TokenFormat() string {
+	return "v4.public"
+}
+
+// TokenFormat returns "apikey/<prefix>"
+func (manager *APIKeyManager) TokenFormat() string {
+	return "apikey/" + manager.prefix
+}
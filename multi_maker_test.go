@@ -0,0 +1,144 @@
+package token
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+// countingMaker counts the tokens it is asked to verify
+type countingMaker struct {
+	Maker
+	verified int
+}
+
+func (maker *countingMaker) VerifyToken(token string) (*Payload, error) {
+	maker.verified++
+	return maker.Maker.VerifyToken(token)
+}
+
+func TestMultiMaker(t *testing.T) {
+	jwtMaker, err := NewJWTMaker(randomString(32))
+	require.NoError(t, err)
+	v2Local, err := NewPasetoV2Local(paseto.NewV2SymmetricKey().ExportHex())
+	require.NoError(t, err)
+	v3SecretKey := paseto.NewV3AsymmetricSecretKey()
+	v3Public, err := NewPasetoV3Public(v3SecretKey.ExportHex(), v3SecretKey.Public().ExportHex())
+	require.NoError(t, err)
+
+	multi, err := NewMultiMaker(v3Public, jwtMaker, v2Local)
+	require.NoError(t, err)
+	require.Equal(t, "v3.public", multi.PrimaryFormat())
+	require.Equal(t, []string{"jwt/HS256", "v2.local", "v3.public"}, multi.Formats())
+
+	t.Run("CreatesWithPrimary", func(t *testing.T) {
+		token, payload, err := multi.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		format, err := DetectTokenFormat(token)
+		require.NoError(t, err)
+		require.Equal(t, "v3.public", format)
+
+		verifiedPayload, err := v3Public.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verifiedPayload.ID)
+	})
+
+	t.Run("VerifiesEveryFormat", func(t *testing.T) {
+		for _, maker := range []Maker{jwtMaker, v2Local, v3Public} {
+			token, payload, err := maker.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := multi.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+		}
+	})
+
+	t.Run("UnregisteredFormat", func(t *testing.T) {
+		v4Local, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		asymJWTMaker, err := NewAsymJWTMaker(privateKey, publicKey)
+		require.NoError(t, err)
+
+		for _, maker := range []Maker{v4Local, asymJWTMaker} {
+			token, _, err := maker.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+
+			payload, err := multi.VerifyToken(token)
+			require.Nil(t, payload)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			require.Equal(t, ReasonWrongAlgorithm, VerificationReasonOf(err))
+		}
+	})
+
+	t.Run("MalformedToken", func(t *testing.T) {
+		for _, token := range []string{"", "not-a-token", "a.b.c", "v9.local.abc"} {
+			_, err := multi.VerifyToken(token)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			require.Equal(t, ReasonMalformed, VerificationReasonOf(err))
+		}
+	})
+
+	t.Run("NeverTriesOtherMakers", func(t *testing.T) {
+		counting := &countingMaker{Maker: jwtMaker}
+		routed, err := NewMultiMaker(v2Local)
+		require.NoError(t, err)
+		require.NoError(t, routed.RegisterFormat("jwt/HS256", counting))
+
+		token, _, err := v3Public.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = routed.VerifyToken(token)
+		require.Equal(t, ReasonWrongAlgorithm, VerificationReasonOf(err))
+		require.Zero(t, counting.verified)
+
+		token, _, err = jwtMaker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = routed.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, 1, counting.verified)
+	})
+
+	t.Run("Registration", func(t *testing.T) {
+		_, err := NewMultiMaker(jwtMaker, jwtMaker)
+		require.Error(t, err)
+
+		_, err = NewMultiMaker(NewRevocableMaker(jwtMaker, NewMemoryRevocationStore()))
+		require.Error(t, err)
+
+		_, err = NewMultiMaker(nil, jwtMaker)
+		require.Error(t, err)
+
+		routed, err := NewMultiMaker(jwtMaker, v2Local)
+		require.NoError(t, err)
+		require.Error(t, routed.Unregister("jwt/HS256"))
+		require.NoError(t, routed.Unregister("v2.local"))
+
+		token, _, err := v2Local.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		_, err = routed.VerifyToken(token)
+		require.Equal(t, ReasonWrongAlgorithm, VerificationReasonOf(err))
+	})
+}
+
+func TestDetectTokenFormat(t *testing.T) {
+	for name, maker := range newTestMakers(t) {
+		t.Run(name, func(t *testing.T) {
+			formatMaker, ok := maker.(FormatMaker)
+			require.True(t, ok)
+
+			token, _, err := maker.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+
+			format, err := DetectTokenFormat(token)
+			require.NoError(t, err)
+			require.Equal(t, formatMaker.TokenFormat(), format)
+		})
+	}
+}
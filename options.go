@@ -0,0 +1,194 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Errors returned when a token's registered claims don't match what the maker expects
+var (
+	ErrInvalidIssuer   = fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	ErrInvalidAudience = fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+)
+
+// Option configures how a maker creates and verifies its tokens
+type Option func(*options)
+
+type options struct {
+	keyID            string
+	paserkKeyID      bool
+	pasetoBinding    Binding
+	issuer           string
+	audience         Audience
+	expectedIssuer   string
+	expectedAudience string
+
+	// used to stamp and check the payload's times
+	clock  Clock
+	leeway time.Duration
+
+	// used by JWEMaker to sign tokens before encrypting them
+	signer Maker
+
+	// used by makers fetching remote keys
+	httpClient      *http.Client
+	refreshInterval time.Duration
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithKeyID sets the ID of the maker's key. It is written to the "kid" JWT header or PASETO footer
+// of every token the maker creates, so a Keyring can tell which key to verify it with
+func WithKeyID(keyID string) Option {
+	return func(o *options) {
+		o.keyID = keyID
+	}
+}
+
+// WithIssuer sets the "iss" claim of every token the maker creates
+func WithIssuer(issuer string) Option {
+	return func(o *options) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience sets the "aud" claim of every token the maker creates
+func WithAudience(audience ...string) Option {
+	return func(o *options) {
+		o.audience = audience
+	}
+}
+
+// WithExpectedIssuer makes VerifyToken reject tokens not issued by issuer
+func WithExpectedIssuer(issuer string) Option {
+	return func(o *options) {
+		o.expectedIssuer = issuer
+	}
+}
+
+// WithExpectedAudience makes VerifyToken reject tokens not intended for audience
+func WithExpectedAudience(audience string) Option {
+	return func(o *options) {
+		o.expectedAudience = audience
+	}
+}
+
+// WithHTTPClient sets the client a JWKSVerifier fetches its key set with
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithRefreshInterval sets how often a JWKSVerifier may refetch its key set when it sees an unknown key ID
+func WithRefreshInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.refreshInterval = interval
+	}
+}
+
+// WithNestedSigner makes a JWEMaker sign its tokens as JWTs with signer before encrypting them (a nested JWT,
+// RFC 7519 section 5.2), and verify the inner JWT with signer after decrypting them
+func WithNestedSigner(signer Maker) Option {
+	return func(o *options) {
+		o.signer = signer
+	}
+}
+
+// WithClock makes the maker read the current time from clock instead of the system clock, both when
+// stamping new payloads and when checking the expiry of verified ones
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithLeeway makes VerifyToken accept tokens that expired, or only become valid, within leeway of the
+// current time, to tolerate clock skew between the machines creating and verifying them
+func WithLeeway(leeway time.Duration) Option {
+	return func(o *options) {
+		o.leeway = leeway
+	}
+}
+
+// KeyID returns the ID of the maker's key, empty when the maker wasn't given one
+func (o options) KeyID() string {
+	return o.keyID
+}
+
+// now returns the current time according to the maker's clock
+func (o options) now() time.Time {
+	if o.clock == nil {
+		return time.Now()
+	}
+	return o.clock.Now()
+}
+
+// stamp fills in the registered claims the maker is configured to set, unless the payload already has them
+func (o options) stamp(payload *Payload) {
+	if payload.Issuer == "" {
+		payload.Issuer = o.issuer
+	}
+	if len(payload.Audience) == 0 && len(o.audience) > 0 {
+		payload.Audience = append(Audience(nil), o.audience...)
+	}
+}
+
+// verify checks the registered claims of a parsed payload against the maker's expectations
+func (o options) verify(payload *Payload) error {
+	if err := payload.ValidAt(o.now(), o.leeway); err != nil {
+		return err
+	}
+
+	if o.expectedIssuer != "" && payload.Issuer != o.expectedIssuer {
+		return newVerificationError(ReasonWrongIssuer, nil)
+	}
+
+	if o.expectedAudience != "" && !payload.Audience.Contains(o.expectedAudience) {
+		return newVerificationError(ReasonWrongAudience, nil)
+	}
+
+	return nil
+}
+
+// optionsMaker is implemented by the makers built with options, which embed them, and by the makers wrapping
+// them, which return the options of the maker they create tokens with
+type optionsMaker interface {
+	makerOptions() options
+}
+
+func (o options) makerOptions() options {
+	return o
+}
+
+// optionsOf returns the options maker was built with, the defaults for makers that don't expose theirs
+func optionsOf(maker Maker) options {
+	if maker, ok := maker.(optionsMaker); ok {
+		return maker.makerOptions()
+	}
+	return options{}
+}
+
+// footer returns the PASETO footer for a token with the given binding, nil when there's nothing to put in it
+func (o options) footer(binding Binding) ([]byte, error) {
+	if o.keyID == "" && len(binding.Footer) == 0 {
+		return nil, nil
+	}
+
+	footer := make(map[string]string, len(binding.Footer)+1)
+	for name, value := range binding.Footer {
+		footer[name] = value
+	}
+	if o.keyID != "" {
+		footer["kid"] = o.keyID
+	}
+	return json.Marshal(footer)
+}
@@ -0,0 +1,330 @@
+package token
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"aidanwoods.dev/go-paseto"
+	"golang.org/x/crypto/blake2b"
+)
+
+// PASERK key types (https://github.com/paseto-standard/paserk)
+const (
+	paserkLocal  = "local"
+	paserkSecret = "secret"
+	paserkPublic = "public"
+)
+
+// paserkIDTypes maps a PASERK key type to the type of its key ID
+var paserkIDTypes = map[string]string{paserkLocal: "lid", paserkSecret: "sid", paserkPublic: "pid"}
+
+// paserkHeader matches the "kN.type." header of a PASERK
+var paserkHeader = regexp.MustCompile(`^k([1-4])\.([a-z-]+)\.`)
+
+// paserkIDSize is the size of the hash in a PASERK key ID
+const paserkIDSize = 33
+
+// WithPASERKKeyID makes a PASETO maker use the PASERK ID of its key (k4.lid for local keys, k4.pid for public
+// ones) as its key ID, replacing any WithKeyID
+func WithPASERKKeyID() Option {
+	return func(o *options) {
+		o.paserkKeyID = true
+	}
+}
+
+// usePASERKKeyID sets the maker's key ID to the PASERK ID of its key when asked to by WithPASERKKeyID
+func (o *options) usePASERKKeyID(paserkID func() string) {
+	if o.paserkKeyID {
+		o.keyID = paserkID()
+	}
+}
+
+// PASERKKeyID returns the key ID of a local, secret or public PASERK: its lid, sid or pid
+func PASERKKeyID(paserk string) (string, error) {
+	match := paserkHeader.FindStringSubmatch(paserk)
+	if match == nil {
+		return "", fmt.Errorf("invalid PASERK")
+	}
+
+	idType, ok := paserkIDTypes[match[2]]
+	if !ok {
+		return "", fmt.Errorf("PASERK type %q has no key ID", match[2])
+	}
+
+	header := "k" + match[1] + "." + idType + "."
+	var digest []byte
+	switch match[1] {
+	case "1", "3":
+		sum := sha512.Sum384([]byte(header + paserk))
+		digest = sum[:paserkIDSize]
+	default:
+		hash, err := blake2b.New(paserkIDSize, nil)
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte(header + paserk))
+		digest = hash.Sum(nil)
+	}
+
+	return header + base64.RawURLEncoding.EncodeToString(digest), nil
+}
+
+func encodePASERK(version int, keyType string, key []byte) string {
+	return fmt.Sprintf("k%d.%s.%s", version, keyType, base64.RawURLEncoding.EncodeToString(key))
+}
+
+// decodePASERK returns the key in a PASERK, rejecting PASERKs of another version or type
+func decodePASERK(paserk string, version int, keyType string) ([]byte, error) {
+	match := paserkHeader.FindStringSubmatch(paserk)
+	if match == nil {
+		return nil, fmt.Errorf("invalid PASERK")
+	}
+
+	want := fmt.Sprintf("k%d.%s", version, keyType)
+	if got := "k" + match[1] + "." + match[2]; got != want {
+		return nil, fmt.Errorf("PASERK is a %s key, expected %s", got, want)
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(paserk[len(match[0]):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid PASERK encoding: %w", err)
+	}
+	return key, nil
+}
+
+// mustPASERKKeyID is PASERKKeyID for PASERKs built by this package, which always have a key ID
+func mustPASERKKeyID(paserk string) string {
+	id, err := PASERKKeyID(paserk)
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// NewPasetoV2LocalFromPASERK initializes a PASETO V2 Local maker from a k2.local PASERK
+func NewPasetoV2LocalFromPASERK(paserk string, opts ...Option) (*PasetoV2Local, error) {
+	key, err := decodePASERK(paserk, 2, paserkLocal)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV2Local(hex.EncodeToString(key), opts...)
+}
+
+// PASERK exports the maker's key as a k2.local PASERK
+func (maker *PasetoV2Local) PASERK() string {
+	return encodePASERK(2, paserkLocal, maker.symmetricKey.ExportBytes())
+}
+
+// PASERKID returns the k2.lid ID of the maker's key
+func (maker *PasetoV2Local) PASERKID() string {
+	return mustPASERKKeyID(maker.PASERK())
+}
+
+// NewPasetoV3LocalFromPASERK initializes a PASETO V3 Local maker from a k3.local PASERK
+func NewPasetoV3LocalFromPASERK(paserk string, opts ...Option) (*PasetoV3Local, error) {
+	key, err := decodePASERK(paserk, 3, paserkLocal)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV3Local(hex.EncodeToString(key), opts...)
+}
+
+// PASERK exports the maker's key as a k3.local PASERK
+func (maker *PasetoV3Local) PASERK() string {
+	return encodePASERK(3, paserkLocal, maker.symmetricKey.ExportBytes())
+}
+
+// PASERKID returns the k3.lid ID of the maker's key
+func (maker *PasetoV3Local) PASERKID() string {
+	return mustPASERKKeyID(maker.PASERK())
+}
+
+// NewPasetoV4LocalFromPASERK initializes a PASETO V4 Local maker from a k4.local PASERK
+func NewPasetoV4LocalFromPASERK(paserk string, opts ...Option) (*PasetoV4Local, error) {
+	key, err := decodePASERK(paserk, 4, paserkLocal)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV4LocalFromBytes(key, opts...)
+}
+
+// PASERK exports the maker's key as a k4.local PASERK
+func (maker *PasetoV4Local) PASERK() string {
+	return encodePASERK(4, paserkLocal, maker.symmetricKey.ExportBytes())
+}
+
+// PASERKID returns the k4.lid ID of the maker's key
+func (maker *PasetoV4Local) PASERKID() string {
+	return mustPASERKKeyID(maker.PASERK())
+}
+
+// NewPasetoV2PublicFromPASERK initializes a PASETO V2 Public maker from a k2.secret and a k2.public PASERK.
+// The public key is derived from the private key when publicPASERK is empty
+func NewPasetoV2PublicFromPASERK(secretPASERK, publicPASERK string, opts ...Option) (*PasetoV2Public, error) {
+	secretKey, err := decodePASERK(secretPASERK, 2, paserkSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKey []byte
+	if publicPASERK == "" {
+		privateKey, err := paseto.NewV2AsymmetricSecretKeyFromBytes(secretKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
+		}
+		publicKey = privateKey.Public().ExportBytes()
+	} else if publicKey, err = decodePASERK(publicPASERK, 2, paserkPublic); err != nil {
+		return nil, err
+	}
+	return NewPasetoV2Public(hex.EncodeToString(secretKey), hex.EncodeToString(publicKey), opts...)
+}
+
+// NewPasetoV2PublicVerifierFromPASERK initializes a PASETO V2 Public verifier from a k2.public PASERK
+func NewPasetoV2PublicVerifierFromPASERK(publicPASERK string, opts ...Option) (*PasetoV2Public, error) {
+	publicKey, err := decodePASERK(publicPASERK, 2, paserkPublic)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV2PublicVerifier(hex.EncodeToString(publicKey), opts...)
+}
+
+// SecretPASERK exports the maker's private key as a k2.secret PASERK, empty when the maker holds no private key
+func (maker *PasetoV2Public) SecretPASERK() string {
+	if maker.privateKey == nil {
+		return ""
+	}
+	return encodePASERK(2, paserkSecret, maker.privateKey.ExportBytes())
+}
+
+// PublicPASERK exports the maker's public key as a k2.public PASERK
+func (maker *PasetoV2Public) PublicPASERK() string {
+	return encodePASERK(2, paserkPublic, maker.publicKey.ExportBytes())
+}
+
+// PASERKID returns the k2.pid ID of the maker's public key
+func (maker *PasetoV2Public) PASERKID() string {
+	return mustPASERKKeyID(maker.PublicPASERK())
+}
+
+// SecretPASERKID returns the k2.sid ID of the maker's private key, empty when the maker holds no private key
+func (maker *PasetoV2Public) SecretPASERKID() string {
+	if maker.privateKey == nil {
+		return ""
+	}
+	return mustPASERKKeyID(maker.SecretPASERK())
+}
+
+// NewPasetoV3PublicFromPASERK initializes a PASETO V3 Public maker from a k3.secret and a k3.public PASERK.
+// The public key is derived from the private key when publicPASERK is empty
+func NewPasetoV3PublicFromPASERK(secretPASERK, publicPASERK string, opts ...Option) (*PasetoV3Public, error) {
+	secretKey, err := decodePASERK(secretPASERK, 3, paserkSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKey []byte
+	if publicPASERK == "" {
+		privateKey, err := paseto.NewV3AsymmetricSecretKeyFromBytes(secretKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
+		}
+		publicKey = privateKey.Public().ExportBytes()
+	} else if publicKey, err = decodePASERK(publicPASERK, 3, paserkPublic); err != nil {
+		return nil, err
+	}
+	return NewPasetoV3Public(hex.EncodeToString(secretKey), hex.EncodeToString(publicKey), opts...)
+}
+
+// NewPasetoV3PublicVerifierFromPASERK initializes a PASETO V3 Public verifier from a k3.public PASERK
+func NewPasetoV3PublicVerifierFromPASERK(publicPASERK string, opts ...Option) (*PasetoV3Public, error) {
+	publicKey, err := decodePASERK(publicPASERK, 3, paserkPublic)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV3PublicVerifier(hex.EncodeToString(publicKey), opts...)
+}
+
+// SecretPASERK exports the maker's private key as a k3.secret PASERK, empty when the maker holds no private key
+func (maker *PasetoV3Public) SecretPASERK() string {
+	if maker.privateKey == nil {
+		return ""
+	}
+	return encodePASERK(3, paserkSecret, maker.privateKey.ExportBytes())
+}
+
+// PublicPASERK exports the maker's public key as a k3.public PASERK
+func (maker *PasetoV3Public) PublicPASERK() string {
+	return encodePASERK(3, paserkPublic, maker.publicKey.ExportBytes())
+}
+
+// PASERKID returns the k3.pid ID of the maker's public key
+func (maker *PasetoV3Public) PASERKID() string {
+	return mustPASERKKeyID(maker.PublicPASERK())
+}
+
+// SecretPASERKID returns the k3.sid ID of the maker's private key, empty when the maker holds no private key
+func (maker *PasetoV3Public) SecretPASERKID() string {
+	if maker.privateKey == nil {
+		return ""
+	}
+	return mustPASERKKeyID(maker.SecretPASERK())
+}
+
+// NewPasetoV4PublicFromPASERK initializes a PASETO V4 Public maker from a k4.secret and a k4.public PASERK.
+// The public key is derived from the private key when publicPASERK is empty
+func NewPasetoV4PublicFromPASERK(secretPASERK, publicPASERK string, opts ...Option) (*PasetoV4Public, error) {
+	secretKey, err := decodePASERK(secretPASERK, 4, paserkSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKey []byte
+	if publicPASERK == "" {
+		privateKey, err := paseto.NewV4AsymmetricSecretKeyFromBytes(secretKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
+		}
+		publicKey = privateKey.Public().ExportBytes()
+	} else if publicKey, err = decodePASERK(publicPASERK, 4, paserkPublic); err != nil {
+		return nil, err
+	}
+	return NewPasetoV4PublicFromBytes(secretKey, publicKey, opts...)
+}
+
+// NewPasetoV4PublicVerifierFromPASERK initializes a PASETO V4 Public verifier from a k4.public PASERK
+func NewPasetoV4PublicVerifierFromPASERK(publicPASERK string, opts ...Option) (*PasetoV4Public, error) {
+	publicKey, err := decodePASERK(publicPASERK, 4, paserkPublic)
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV4PublicVerifier(hex.EncodeToString(publicKey), opts...)
+}
+
+// SecretPASERK exports the maker's private key as a k4.secret PASERK, empty when the maker holds no private key
+func (maker *PasetoV4Public) SecretPASERK() string {
+	if maker.privateKey == nil {
+		return ""
+	}
+	return encodePASERK(4, paserkSecret, maker.privateKey.ExportBytes())
+}
+
+// PublicPASERK exports the maker's public key as a k4.public PASERK
+func (maker *PasetoV4Public) PublicPASERK() string {
+	return encodePASERK(4, paserkPublic, maker.publicKey.ExportBytes())
+}
+
+// PASERKID returns the k4.pid ID of the maker's public key
+func (maker *PasetoV4Public) PASERKID() string {
+	return mustPASERKKeyID(maker.PublicPASERK())
+}
+
+// SecretPASERKID returns the k4.sid ID of the maker's private key, empty when the maker holds no private key
+func (maker *PasetoV4Public) SecretPASERKID() string {
+	if maker.privateKey == nil {
+		return ""
+	}
+	return mustPASERKKeyID(maker.SecretPASERK())
+}
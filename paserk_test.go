@@ -0,0 +1,142 @@
+package token
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPASERKKeyID(t *testing.T) {
+	// Test vector from the PASERK specification
+	id, err := PASERKKeyID("k4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+	require.NoError(t, err)
+	require.Equal(t, "k4.lid.bqltbNc4JLUAmc9Xtpok-fBuI0dQN5_m3CD9W_nbh559", id)
+
+	id, err = PASERKKeyID("k3.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(id, "k3.lid."))
+	require.Len(t, id, len("k3.lid.")+44)
+
+	_, err = PASERKKeyID("k4.lid.bqltbNc4JLUAmc9Xtpok-fBuI0dQN5_m3CD9W_nbh559")
+	require.Error(t, err)
+	_, err = PASERKKeyID("not a paserk")
+	require.Error(t, err)
+}
+
+func TestPASERK(t *testing.T) {
+	t.Run("Local", func(t *testing.T) {
+		v2, err := NewPasetoV2Local(paseto.NewV2SymmetricKey().ExportHex())
+		require.NoError(t, err)
+		v3, err := NewPasetoV3Local(paseto.NewV3SymmetricKey().ExportHex())
+		require.NoError(t, err)
+		v4, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+
+		require.True(t, strings.HasPrefix(v2.PASERK(), "k2.local."))
+		require.True(t, strings.HasPrefix(v3.PASERK(), "k3.local."))
+		require.True(t, strings.HasPrefix(v4.PASERK(), "k4.local."))
+		require.True(t, strings.HasPrefix(v4.PASERKID(), "k4.lid."))
+
+		imported := map[Maker]func() (Maker, error){
+			v2: func() (Maker, error) { return NewPasetoV2LocalFromPASERK(v2.PASERK()) },
+			v3: func() (Maker, error) { return NewPasetoV3LocalFromPASERK(v3.PASERK()) },
+			v4: func() (Maker, error) { return NewPasetoV4LocalFromPASERK(v4.PASERK()) },
+		}
+		for original, load := range imported {
+			maker, err := load()
+			require.NoError(t, err)
+			requireSameKey(t, original, maker)
+		}
+
+		// A key of another version doesn't silently load
+		_, err = NewPasetoV3LocalFromPASERK(v2.PASERK())
+		require.EqualError(t, err, "PASERK is a k2.local key, expected k3.local")
+		_, err = NewPasetoV4LocalFromPASERK(v4.PASERKID())
+		require.Error(t, err)
+	})
+
+	t.Run("Public", func(t *testing.T) {
+		v2Secret := paseto.NewV2AsymmetricSecretKey()
+		v2, err := NewPasetoV2Public(v2Secret.ExportHex(), v2Secret.Public().ExportHex())
+		require.NoError(t, err)
+		v3Secret := paseto.NewV3AsymmetricSecretKey()
+		v3, err := NewPasetoV3Public(v3Secret.ExportHex(), v3Secret.Public().ExportHex())
+		require.NoError(t, err)
+		v4Secret := paseto.NewV4AsymmetricSecretKey()
+		v4, err := NewPasetoV4Public(v4Secret.ExportHex(), v4Secret.Public().ExportHex())
+		require.NoError(t, err)
+
+		require.True(t, strings.HasPrefix(v3.SecretPASERK(), "k3.secret."))
+		require.True(t, strings.HasPrefix(v3.PublicPASERK(), "k3.public."))
+		require.True(t, strings.HasPrefix(v3.PASERKID(), "k3.pid."))
+		require.True(t, strings.HasPrefix(v3.SecretPASERKID(), "k3.sid."))
+
+		imported := map[Maker]func() (Maker, error){
+			v2: func() (Maker, error) { return NewPasetoV2PublicFromPASERK(v2.SecretPASERK(), v2.PublicPASERK()) },
+			v3: func() (Maker, error) { return NewPasetoV3PublicFromPASERK(v3.SecretPASERK(), v3.PublicPASERK()) },
+			v4: func() (Maker, error) { return NewPasetoV4PublicFromPASERK(v4.SecretPASERK(), v4.PublicPASERK()) },
+		}
+		for original, load := range imported {
+			maker, err := load()
+			require.NoError(t, err)
+			requireSameKey(t, original, maker)
+		}
+
+		// The public key can be left out
+		derived := map[Maker]func() (Maker, error){
+			v2: func() (Maker, error) { return NewPasetoV2PublicFromPASERK(v2.SecretPASERK(), "") },
+			v3: func() (Maker, error) { return NewPasetoV3PublicFromPASERK(v3.SecretPASERK(), "") },
+			v4: func() (Maker, error) { return NewPasetoV4PublicFromPASERK(v4.SecretPASERK(), "") },
+		}
+		for original, load := range derived {
+			maker, err := load()
+			require.NoError(t, err)
+			requireSameKey(t, original, maker)
+		}
+
+		// Purposes can't be swapped either
+		_, err = NewPasetoV4PublicFromPASERK(v4.PublicPASERK(), v4.SecretPASERK())
+		require.Error(t, err)
+		_, err = NewPasetoV2PublicFromPASERK(v4.SecretPASERK(), v4.PublicPASERK())
+		require.Error(t, err)
+	})
+
+	t.Run("KeyIDInFooter", func(t *testing.T) {
+		key := paseto.NewV4SymmetricKey().ExportHex()
+		maker, err := NewPasetoV4Local(key, WithKeyID("ignored"), WithPASERKKeyID())
+		require.NoError(t, err)
+		require.Equal(t, maker.PASERKID(), maker.KeyID())
+
+		token, _, err := maker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		keyID, err := tokenKeyID(token)
+		require.NoError(t, err)
+		require.Equal(t, maker.PASERKID(), keyID)
+
+		// So keyrings can dispatch on it
+		keyring, err := NewKeyring(maker)
+		require.NoError(t, err)
+		_, err = keyring.VerifyToken(token)
+		require.NoError(t, err)
+
+		secret := paseto.NewV4AsymmetricSecretKey()
+		public, err := NewPasetoV4Public(secret.ExportHex(), secret.Public().ExportHex(), WithPASERKKeyID())
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(public.KeyID(), "k4.pid."))
+	})
+}
+
+// requireSameKey checks that tokens created by one maker verify with the other
+func requireSameKey(t *testing.T, original, imported Maker) {
+	t.Helper()
+
+	token, payload, err := original.CreateToken("alice", time.Minute)
+	require.NoError(t, err)
+
+	verifiedPayload, err := imported.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, payload.ID, verifiedPayload.ID)
+}
@@ -0,0 +1,95 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Errors returned when a PASETO token isn't bound to what the maker expects
+var (
+	ErrInvalidFooter                = fmt.Errorf("%w: unexpected footer", ErrInvalidToken)
+	ErrImplicitAssertionUnsupported = errors.New("PASETO v2 doesn't support implicit assertions")
+)
+
+// Binding ties a PASETO token to data outside of its payload. Both parts are authenticated along with the
+// payload, so a token only verifies against the same binding it was created with
+type Binding struct {
+	// Footer entries are written to the token's JSON footer next to its "kid", e.g. a "wpk" wrapped key.
+	// The footer is readable without the key. When verifying, every entry must be present with the same value
+	Footer map[string]string
+
+	// ImplicitAssertion is authenticated but never written to the token (PASETO v3 and v4 only), e.g. the
+	// ID of the tenant the token is valid for
+	ImplicitAssertion []byte
+}
+
+// BindingMaker is implemented by the PASETO makers, whose tokens can be bound to a footer and implicit
+// assertion for each token
+type BindingMaker interface {
+	ClaimsMaker
+	CreateBoundToken(payload *Payload, binding Binding) (string, error)
+	VerifyBoundToken(token string, binding Binding) (*Payload, error)
+}
+
+// WithFooter adds entries to the JSON footer of every token a PASETO maker creates, and requires them
+// in every token it verifies
+func WithFooter(footer map[string]string) Option {
+	return func(o *options) {
+		o.pasetoBinding.Footer = footer
+	}
+}
+
+// WithImplicitAssertion binds every token a PASETO v3 or v4 maker creates or verifies to assertion
+func WithImplicitAssertion(assertion []byte) Option {
+	return func(o *options) {
+		o.pasetoBinding.ImplicitAssertion = assertion
+	}
+}
+
+// binding merges the binding given for one token into the maker's own. Footer entries are added to the maker's,
+// an implicit assertion replaces the maker's
+func (o options) binding(binding Binding) Binding {
+	merged := Binding{ImplicitAssertion: o.pasetoBinding.ImplicitAssertion}
+	if binding.ImplicitAssertion != nil {
+		merged.ImplicitAssertion = binding.ImplicitAssertion
+	}
+
+	if len(o.pasetoBinding.Footer) > 0 || len(binding.Footer) > 0 {
+		merged.Footer = make(map[string]string, len(o.pasetoBinding.Footer)+len(binding.Footer))
+		for name, value := range o.pasetoBinding.Footer {
+			merged.Footer[name] = value
+		}
+		for name, value := range binding.Footer {
+			merged.Footer[name] = value
+		}
+	}
+	return merged
+}
+
+// supportedByV2 rejects bindings PASETO v2 can't express
+func (binding Binding) supportedByV2() error {
+	if len(binding.ImplicitAssertion) > 0 {
+		return ErrImplicitAssertionUnsupported
+	}
+	return nil
+}
+
+// checkFooter checks that a verified token's footer holds every entry the binding expects
+func checkFooter(footer []byte, binding Binding) error {
+	if len(binding.Footer) == 0 {
+		return nil
+	}
+
+	var entries map[string]interface{}
+	if err := json.Unmarshal(footer, &entries); err != nil {
+		return newVerificationError(ReasonWrongFooter, nil)
+	}
+
+	for name, want := range binding.Footer {
+		if got, ok := entries[name].(string); !ok || got != want {
+			return newVerificationError(ReasonWrongFooter, nil)
+		}
+	}
+	return nil
+}
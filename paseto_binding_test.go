@@ -0,0 +1,117 @@
+package token
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoBinding(t *testing.T) {
+	for name, maker := range newTestMakers(t, WithKeyID("kid-1")) {
+		bindingMaker, ok := maker.(BindingMaker)
+		if !ok {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Run("Footer", func(t *testing.T) {
+				payload, err := NewPayload("alice", time.Minute)
+				require.NoError(t, err)
+
+				token, err := bindingMaker.CreateBoundToken(payload, Binding{Footer: map[string]string{"wpk": "k4.local-wrap.pie.abc"}})
+				require.NoError(t, err)
+
+				parts := strings.Split(token, ".")
+				require.Len(t, parts, 4)
+				footer, err := base64.RawURLEncoding.DecodeString(parts[3])
+				require.NoError(t, err)
+				require.JSONEq(t, `{"kid":"kid-1","wpk":"k4.local-wrap.pie.abc"}`, string(footer))
+
+				_, err = bindingMaker.VerifyBoundToken(token, Binding{Footer: map[string]string{"wpk": "k4.local-wrap.pie.abc"}})
+				require.NoError(t, err)
+
+				// Plain verification doesn't expect any footer entries
+				_, err = maker.VerifyToken(token)
+				require.NoError(t, err)
+
+				_, err = bindingMaker.VerifyBoundToken(token, Binding{Footer: map[string]string{"wpk": "k4.local-wrap.pie.xyz"}})
+				require.ErrorIs(t, err, ErrInvalidFooter)
+				require.ErrorIs(t, err, ErrInvalidToken)
+			})
+
+			if strings.HasPrefix(name, "PasetoV2") {
+				t.Run("ImplicitAssertionUnsupported", func(t *testing.T) {
+					payload, err := NewPayload("alice", time.Minute)
+					require.NoError(t, err)
+					_, err = bindingMaker.CreateBoundToken(payload, Binding{ImplicitAssertion: []byte("tenant:acme")})
+					require.ErrorIs(t, err, ErrImplicitAssertionUnsupported)
+				})
+				return
+			}
+
+			t.Run("ImplicitAssertion", func(t *testing.T) {
+				payload, err := NewPayload("alice", time.Minute)
+				require.NoError(t, err)
+
+				token, err := bindingMaker.CreateBoundToken(payload, Binding{ImplicitAssertion: []byte("tenant:acme")})
+				require.NoError(t, err)
+				require.NotContains(t, token, base64.RawURLEncoding.EncodeToString([]byte("tenant:acme")))
+
+				verifiedPayload, err := bindingMaker.VerifyBoundToken(token, Binding{ImplicitAssertion: []byte("tenant:acme")})
+				require.NoError(t, err)
+				require.Equal(t, payload.ID, verifiedPayload.ID)
+
+				_, err = bindingMaker.VerifyBoundToken(token, Binding{ImplicitAssertion: []byte("tenant:globex")})
+				require.Error(t, err)
+				_, err = maker.VerifyToken(token)
+				require.Error(t, err)
+			})
+		})
+	}
+
+	t.Run("MakerOptions", func(t *testing.T) {
+		key := paseto.NewV3SymmetricKey().ExportHex()
+		acme, err := NewPasetoV3Local(key, WithFooter(map[string]string{"tenant_hint": "eu"}), WithImplicitAssertion([]byte("tenant:acme")))
+		require.NoError(t, err)
+		globex, err := NewPasetoV3Local(key, WithFooter(map[string]string{"tenant_hint": "eu"}), WithImplicitAssertion([]byte("tenant:globex")))
+		require.NoError(t, err)
+		unbound, err := NewPasetoV3Local(key)
+		require.NoError(t, err)
+
+		token, payload, err := acme.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		verifiedPayload, err := acme.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, payload.ID, verifiedPayload.ID)
+
+		_, err = globex.VerifyToken(token)
+		require.Error(t, err)
+
+		// A token without the expected footer entries is rejected
+		unboundToken, err := unbound.CreateTokenFromPayload(payload)
+		require.NoError(t, err)
+		_, err = acme.VerifyBoundToken(unboundToken, Binding{ImplicitAssertion: []byte{}})
+		require.ErrorIs(t, err, ErrInvalidFooter)
+
+		// The per-token binding is merged into the maker's
+		token, err = acme.CreateBoundToken(payload, Binding{Footer: map[string]string{"wpk": "abc"}})
+		require.NoError(t, err)
+		parts := strings.Split(token, ".")
+		footer, err := base64.RawURLEncoding.DecodeString(parts[3])
+		require.NoError(t, err)
+		var entries map[string]string
+		require.NoError(t, json.Unmarshal(footer, &entries))
+		require.Equal(t, map[string]string{"tenant_hint": "eu", "wpk": "abc"}, entries)
+	})
+
+	t.Run("V2RejectsImplicitAssertionOption", func(t *testing.T) {
+		_, err := NewPasetoV2Local(paseto.NewV2SymmetricKey().ExportHex(), WithImplicitAssertion([]byte("tenant:acme")))
+		require.ErrorIs(t, err, ErrImplicitAssertionUnsupported)
+	})
+}
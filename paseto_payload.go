@@ -0,0 +1,140 @@
+package token
+
+import (
+	"aidanwoods.dev/go-paseto"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+)
+
+// newPasetoToken maps a payload onto an unsigned PASETO token using the PASETO registered claim keys,
+// shared by every PASETO maker
+func newPasetoToken(payload *Payload, o options, binding Binding) (paseto.Token, error) {
+	token := paseto.NewToken()
+
+	footer, err := o.footer(binding)
+	if err != nil {
+		return token, fmt.Errorf("could not encode footer: %w", err)
+	}
+	token.SetFooter(footer)
+
+	token.SetJti(payload.ID.String())
+	token.SetIssuedAt(payload.IssuedAt)
+	token.SetNotBefore(payload.NotBefore)
+	token.SetExpiration(payload.ExpiredAt)
+	token.SetString("username", payload.Username)
+
+	if payload.Issuer != "" {
+		token.SetIssuer(payload.Issuer)
+	}
+	if payload.Subject != "" {
+		token.SetSubject(payload.Subject)
+	}
+	if len(payload.Audience) > 0 {
+		if err := token.Set("aud", payload.Audience); err != nil {
+			return token, fmt.Errorf("could not set audience: %w", err)
+		}
+	}
+	if len(payload.Scopes) > 0 {
+		if err := token.Set("scope", payload.Scopes); err != nil {
+			return token, fmt.Errorf("could not set scopes: %w", err)
+		}
+	}
+
+	for name, value := range payload.Claims {
+		if err := token.Set(name, value); err != nil {
+			return token, fmt.Errorf("could not set custom claim %q: %w", name, err)
+		}
+	}
+
+	// set after the custom claims, which may hold roles too: as for JWTs, Roles wins
+	if len(payload.Roles) > 0 {
+		if err := token.Set("roles", payload.Roles); err != nil {
+			return token, fmt.Errorf("could not set roles: %w", err)
+		}
+	}
+
+	return token, nil
+}
+
+// pasetoParser returns the parser a PASETO maker verifies tokens with. go-paseto's expiry rule reads the
+// system clock with no leeway, so expiry and not-before times are left to verify, which honours both
+func (o options) pasetoParser() paseto.Parser {
+	return paseto.NewParserWithoutExpiryCheck()
+}
+
+// payloadFromPasetoToken reads the payload back out of a decrypted or verified PASETO token
+func payloadFromPasetoToken(parsedToken *paseto.Token) (*Payload, error) {
+	idString, err := parsedToken.GetJti()
+	if err != nil {
+		// tokens issued before the registered claim keys were adopted carry the ID as "id"
+		idString, err = parsedToken.GetString("id")
+		if err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return nil, newVerificationError(ReasonMalformed, fmt.Errorf("could not parse guid to string: %w", err))
+	}
+
+	username, err := parsedToken.GetString("username")
+	if err != nil {
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	issuedAt, err := parsedToken.GetIssuedAt()
+	if err != nil {
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	expiredAt, err := parsedToken.GetExpiration()
+	if err != nil {
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(parsedToken.ClaimsJSON(), &claims); err != nil {
+		return nil, newVerificationError(ReasonMalformed, nil)
+	}
+
+	payload := &Payload{
+		ID:        id,
+		Username:  username,
+		IssuedAt:  issuedAt,
+		ExpiredAt: expiredAt,
+	}
+
+	// The remaining registered claims are optional
+	if _, ok := claims["nbf"]; ok {
+		if payload.NotBefore, err = parsedToken.GetNotBefore(); err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+	if _, ok := claims["iss"]; ok {
+		if payload.Issuer, err = parsedToken.GetIssuer(); err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+	if _, ok := claims["sub"]; ok {
+		if payload.Subject, err = parsedToken.GetSubject(); err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+	if _, ok := claims["aud"]; ok {
+		if err := parsedToken.Get("aud", &payload.Audience); err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+	if _, ok := claims["scope"]; ok {
+		if err := parsedToken.Get("scope", &payload.Scopes); err != nil {
+			return nil, newVerificationError(ReasonMalformed, nil)
+		}
+	}
+	payload.Roles = decodeRoles(claims["roles"])
+
+	payload.Claims = customClaimsFrom(claims)
+
+	return payload, nil
+}
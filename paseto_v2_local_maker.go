@@ -4,15 +4,15 @@ import (
 	"aidanwoods.dev/go-paseto"
 	"encoding/hex"
 	"fmt"
-	"github.com/google/uuid"
 	"time"
 )
 
 type PasetoV2Local struct {
 	symmetricKey paseto.V2SymmetricKey
+	options
 }
 
-func NewPasetoV2Local(symmetricKeyHex string) (*PasetoV2Local, error) {
+func NewPasetoV2Local(symmetricKeyHex string, opts ...Option) (*PasetoV2Local, error) {
 	// Decode the hexadecimal symmetric key
 	keyBytes, err := hex.DecodeString(symmetricKeyHex)
 	if err != nil {
@@ -31,63 +31,87 @@ func NewPasetoV2Local(symmetricKeyHex string) (*PasetoV2Local, error) {
 		return nil, fmt.Errorf("could not initialize symmetric keys from bytes : %d", err)
 	}
 
-	return &PasetoV2Local{
+	maker := &PasetoV2Local{
 		symmetricKey: symmetricKey,
-	}, nil
+		options:      newOptions(opts),
+	}
+	maker.options.usePASERKKeyID(maker.PASERKID)
+	if err := maker.options.pasetoBinding.supportedByV2(); err != nil {
+		return nil, err
+	}
+
+	return maker, nil
 }
 
 func (maker *PasetoV2Local) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+// CreateTokenWithClaims creates a new token with the given username, duration and custom claims.
+func (maker *PasetoV2Local) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
 	if err != nil {
-		return "", payload, fmt.Errorf("could not create payload : %d", err)
+		return "", payload, fmt.Errorf("could not create payload : %w", err)
 	}
 
-	token := paseto.NewToken()
-	token.SetIssuedAt(time.Now())
-	token.SetNotBefore(time.Now())
-	token.SetExpiration(time.Now().Add(duration))
-	token.SetString("username", payload.Username)
-	token.SetString("id", payload.ID.String())
-	encryptedToken := token.V2Encrypt(maker.symmetricKey)
-	return encryptedToken, payload, nil
+	token, err := maker.CreateTokenFromPayload(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, payload, nil
 }
 
-func (maker *PasetoV2Local) VerifyToken(token string) (*Payload, error) {
-	parsedToken, err := paseto.NewParser().ParseV2Local(maker.symmetricKey, token)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse payload: %s", err)
+// CreateTokenFromPayload creates a new token for a payload built by the caller, filling in the maker's issuer and audience.
+func (maker *PasetoV2Local) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.CreateBoundToken(payload, Binding{})
+}
+
+// CreateBoundToken creates a new token for a payload, bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV2Local) CreateBoundToken(payload *Payload, binding Binding) (string, error) {
+	binding = maker.options.binding(binding)
+	if err := binding.supportedByV2(); err != nil {
+		return "", err
 	}
 
-	idString, err := parsedToken.GetString("id")
+	maker.options.stamp(payload)
+
+	token, err := newPasetoToken(payload, maker.options, binding)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", err
 	}
 
-	id, err := uuid.Parse(idString)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse guid to string: %s", err)
+	// Encrypt the token using the symmetric key
+	return token.V2Encrypt(maker.symmetricKey), nil
+}
+
+func (maker *PasetoV2Local) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyBoundToken(token, Binding{})
+}
+
+// VerifyBoundToken verifies a token that must be bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV2Local) VerifyBoundToken(token string, binding Binding) (*Payload, error) {
+	binding = maker.options.binding(binding)
+	if err := binding.supportedByV2(); err != nil {
+		return nil, err
 	}
 
-	username, err := parsedToken.GetString("username")
+	parsedToken, err := maker.options.pasetoParser().ParseV2Local(maker.symmetricKey, token)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, pasetoVerificationError(err)
 	}
 
-	issuedAt, err := parsedToken.GetIssuedAt()
-	if err != nil {
-		return nil, ErrInvalidToken
+	if err := checkFooter(parsedToken.Footer(), binding); err != nil {
+		return nil, err
 	}
 
-	expiredAt, err := parsedToken.GetExpiration()
+	payload, err := payloadFromPasetoToken(parsedToken)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, err
 	}
 
-	payload := &Payload{
-		ID:        id,
-		Username:  username,
-		IssuedAt:  issuedAt,
-		ExpiredAt: expiredAt,
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
 	}
 
 	return payload, nil
@@ -2,87 +2,160 @@ package token
 
 import (
 	"aidanwoods.dev/go-paseto"
+	"context"
+	"encoding/hex"
 	"fmt"
-	"github.com/google/uuid"
+	"golang.org/x/crypto/ed25519"
 	"time"
 )
 
 type PasetoV2Public struct {
-	privateKey paseto.V2AsymmetricSecretKey
+	privateKey *paseto.V2AsymmetricSecretKey // nil for a verifier
 	publicKey  paseto.V2AsymmetricPublicKey
+	keySigner  KeySigner
+	options
 }
 
-func NewPasetoV2Public(privateKeyHex, publicKeyHex string) (*PasetoV2Public, error) {
+func NewPasetoV2Public(privateKeyHex, publicKeyHex string, opts ...Option) (*PasetoV2Public, error) {
 	privateKey, err := paseto.NewV2AsymmetricSecretKeyFromHex(privateKeyHex)
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
 	}
 
+	maker, err := NewPasetoV2PublicVerifier(publicKeyHex, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey.Public().ExportHex() != maker.publicKey.ExportHex() {
+		return nil, fmt.Errorf("%w: public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
+	maker.privateKey = &privateKey
+	return maker, nil
+}
+
+// NewPasetoV2PublicVerifier initializes a maker that only verifies tokens, with nothing but the public key (in hex format).
+func NewPasetoV2PublicVerifier(publicKeyHex string, opts ...Option) (*PasetoV2Public, error) {
 	publicKey, err := paseto.NewV2AsymmetricPublicKeyFromHex(publicKeyHex)
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
 	}
 
 	maker := &PasetoV2Public{
-		privateKey: privateKey,
-		publicKey:  publicKey,
+		publicKey: publicKey,
+		options:   newOptions(opts),
+	}
+	maker.options.usePASERKKeyID(maker.PASERKID)
+	if err := maker.options.pasetoBinding.supportedByV2(); err != nil {
+		return nil, err
 	}
 
 	return maker, nil
 }
 
-func (maker *PasetoV2Public) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+// NewPasetoV2PublicWithSigner initializes a maker signing through signer, which holds an Ed25519 key.
+func NewPasetoV2PublicWithSigner(signer KeySigner, opts ...Option) (*PasetoV2Public, error) {
+	publicKey, err := keySignerPublicKey[ed25519.PublicKey](signer, "Ed25519")
 	if err != nil {
-		return "", payload, fmt.Errorf("could not initialize payload: %w", err)
+		return nil, err
 	}
 
-	token := paseto.NewToken()
-	token.SetIssuedAt(time.Now())
-	token.SetNotBefore(time.Now())
-	token.SetExpiration(time.Now().Add(duration))
-	token.SetString("username", payload.Username)
-	token.SetString("id", payload.ID.String())
-	signedToken := token.V2Sign(maker.privateKey)
-	return signedToken, payload, nil
+	maker, err := NewPasetoV2PublicVerifier(hex.EncodeToString(publicKey), opts...)
+	if err != nil {
+		return nil, err
+	}
+	maker.keySigner = signer
+	return maker, nil
 }
 
-func (maker *PasetoV2Public) VerifyToken(token string) (*Payload, error) {
-	parsedToken, err := paseto.NewParser().ParseV2Public(maker.publicKey, token)
+func (maker *PasetoV2Public) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+// CreateTokenWithClaims creates a new token with the given username, duration and custom claims.
+func (maker *PasetoV2Public) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", payload, fmt.Errorf("could not initialize payload: %w", err)
 	}
 
-	idString, err := parsedToken.GetString("id")
+	token, err := maker.CreateTokenFromPayload(payload)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", nil, err
 	}
 
-	id, err := uuid.Parse(idString)
-	if err != nil {
-		return nil, ErrInvalidToken
+	return token, payload, nil
+}
+
+// CreateTokenFromPayload creates a new token for a payload built by the caller, filling in the maker's issuer and audience.
+func (maker *PasetoV2Public) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.CreateBoundToken(payload, Binding{})
+}
+
+// CreateBoundToken creates a new token for a payload, bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV2Public) CreateBoundToken(payload *Payload, binding Binding) (string, error) {
+	return maker.createBoundToken(context.Background(), payload, binding)
+}
+
+func (maker *PasetoV2Public) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	return maker.createBoundToken(ctx, payload, Binding{})
+}
+
+func (maker *PasetoV2Public) createBoundToken(ctx context.Context, payload *Payload, binding Binding) (string, error) {
+	if maker.keySigner == nil && maker.privateKey == nil {
+		return "", ErrVerificationOnly
+	}
+	binding = maker.options.binding(binding)
+	if err := binding.supportedByV2(); err != nil {
+		return "", err
 	}
 
-	username, err := parsedToken.GetString("username")
+	maker.options.stamp(payload)
+
+	token, err := newPasetoToken(payload, maker.options, binding)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", err
+	}
+
+	if maker.keySigner != nil {
+		message := token.ClaimsJSON()
+		signature, err := signEd25519(ctx, maker.keySigner, pae([]byte(paseto.V2Public.Header()), message, token.Footer()))
+		if err != nil {
+			return "", err
+		}
+		return encodePublicPaseto(paseto.V2Public, message, signature, token.Footer()), nil
+	}
+
+	return token.V2Sign(*maker.privateKey), nil
+}
+
+func (maker *PasetoV2Public) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyBoundToken(token, Binding{})
+}
+
+// VerifyBoundToken verifies a token that must be bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV2Public) VerifyBoundToken(token string, binding Binding) (*Payload, error) {
+	binding = maker.options.binding(binding)
+	if err := binding.supportedByV2(); err != nil {
+		return nil, err
 	}
 
-	issuedAt, err := parsedToken.GetIssuedAt()
+	parsedToken, err := maker.options.pasetoParser().ParseV2Public(maker.publicKey, token)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, pasetoVerificationError(err)
+	}
+
+	if err := checkFooter(parsedToken.Footer(), binding); err != nil {
+		return nil, err
 	}
 
-	expiredAt, err := parsedToken.GetExpiration()
+	payload, err := payloadFromPasetoToken(parsedToken)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, err
 	}
 
-	payload := &Payload{
-		ID:        id,
-		Username:  username,
-		IssuedAt:  issuedAt,
-		ExpiredAt: expiredAt,
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
 	}
 
 	return payload, nil
@@ -71,7 +71,7 @@ func TestPasetoV2Public(t *testing.T) {
 		verifiedPayload, err := maker.VerifyToken(token)
 		require.Error(t, err)
 		require.Nil(t, verifiedPayload)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrExpiredToken)
 	})
 
 	t.Run("InvalidToken", func(t *testing.T) {
@@ -81,13 +81,13 @@ func TestPasetoV2Public(t *testing.T) {
 		// Try to verify invalid token
 		verifiedPayload, err := maker.VerifyToken("invalid.token.format")
 		require.Error(t, err)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
 		require.Nil(t, verifiedPayload)
 
 		// Try to verify empty token
 		verifiedPayload, err = maker.VerifyToken("")
 		require.Error(t, err)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
 		require.Nil(t, verifiedPayload)
 	})
 
@@ -103,14 +103,18 @@ func TestPasetoV2Public(t *testing.T) {
 		differentPrivateKey := paseto.NewV2AsymmetricSecretKey()
 		differentPublicKey := differentPrivateKey.Public()
 
-		// Create new maker with different public key
-		wrongMaker, err := NewPasetoV2Public(privateKeyHex, differentPublicKey.ExportHex())
+		// A maker can't be built from a mismatched key pair
+		_, err = NewPasetoV2Public(privateKeyHex, differentPublicKey.ExportHex())
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		// Create a verifier with the different public key
+		wrongMaker, err := NewPasetoV2PublicVerifier(differentPublicKey.ExportHex())
 		require.NoError(t, err)
 
 		// Verify should fail because public key doesn't match
 		verifiedPayload, err := wrongMaker.VerifyToken(token)
 		require.Error(t, err)
-		require.Equal(t, ErrInvalidToken, err)
+		require.ErrorIs(t, err, ErrInvalidToken)
 		require.Nil(t, verifiedPayload)
 	})
 } 
\ No newline at end of file
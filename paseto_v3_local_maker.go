@@ -3,7 +3,6 @@ package token
 import (
 	"encoding/hex"
 	"fmt"
-	"github.com/google/uuid"
 	"time"
 
 	"aidanwoods.dev/go-paseto"
@@ -12,10 +11,11 @@ import (
 // PasetoV3Local handles PASETO V3 Local tokens.
 type PasetoV3Local struct {
 	symmetricKey paseto.V3SymmetricKey
+	options
 }
 
 // NewPasetoV3Local initializes a new PASETO V3 Local instance with the given symmetric key (in hex format).
-func NewPasetoV3Local(symmetricKeyHex string) (*PasetoV3Local, error) {
+func NewPasetoV3Local(symmetricKeyHex string, opts ...Option) (*PasetoV3Local, error) {
 	// Decode the hexadecimal symmetric key
 	keyBytes, err := hex.DecodeString(symmetricKeyHex)
 	if err != nil {
@@ -33,70 +33,80 @@ func NewPasetoV3Local(symmetricKeyHex string) (*PasetoV3Local, error) {
 		return nil, fmt.Errorf("could not initialize symmetric key: %w", err)
 	}
 
-	return &PasetoV3Local{
+	maker := &PasetoV3Local{
 		symmetricKey: symmetricKey,
-	}, nil
+		options:      newOptions(opts),
+	}
+	maker.options.usePASERKKeyID(maker.PASERKID)
+
+	return maker, nil
 }
 
 // CreateToken creates a new PASETO V3 Local token with the given username and duration.
 func (maker *PasetoV3Local) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+// CreateTokenWithClaims creates a new token with the given username, duration and custom claims.
+func (maker *PasetoV3Local) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
 	if err != nil {
 		return "", nil, err
 	}
 
-	// Create a new PASETO token
-	token := paseto.NewToken()
-	token.SetIssuedAt(payload.IssuedAt)
-	token.SetNotBefore(payload.IssuedAt)
-	token.SetExpiration(payload.ExpiredAt)
-	token.SetString("username", payload.Username)
-	token.SetString("id", payload.ID.String())
+	token, err := maker.CreateTokenFromPayload(payload)
+	if err != nil {
+		return "", nil, err
+	}
 
-	// Encrypt the token using the symmetric key
-	encryptedToken := token.V3Encrypt(maker.symmetricKey, nil)
+	return token, payload, nil
+}
 
-	return encryptedToken, payload, nil
+// CreateTokenFromPayload creates a new token for a payload built by the caller, filling in the maker's issuer and audience.
+func (maker *PasetoV3Local) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.CreateBoundToken(payload, Binding{})
 }
 
-// VerifyToken verifies a given PASETO V3 Local token and returns the payload if valid.
-func (maker *PasetoV3Local) VerifyToken(token string) (*Payload, error) {
-	// Parse the encrypted token
-	parsedToken, err := paseto.NewParser().ParseV3Local(maker.symmetricKey, token, nil)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse payload: %s", err)
-	}
+// CreateBoundToken creates a new token for a payload, bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV3Local) CreateBoundToken(payload *Payload, binding Binding) (string, error) {
+	binding = maker.options.binding(binding)
+	maker.options.stamp(payload)
 
-	idString, err := parsedToken.GetString("id")
+	token, err := newPasetoToken(payload, maker.options, binding)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", err
 	}
 
-	id, err := uuid.Parse(idString)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse guid to string: %s", err)
-	}
+	// Encrypt the token using the symmetric key
+	return token.V3Encrypt(maker.symmetricKey, binding.ImplicitAssertion), nil
+}
+
+// VerifyToken verifies a given PASETO V3 Local token and returns the payload if valid.
+func (maker *PasetoV3Local) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyBoundToken(token, Binding{})
+}
 
-	username, err := parsedToken.GetString("username")
+// VerifyBoundToken verifies a token that must be bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV3Local) VerifyBoundToken(token string, binding Binding) (*Payload, error) {
+	binding = maker.options.binding(binding)
+
+	// Parse the encrypted token
+	parsedToken, err := maker.options.pasetoParser().ParseV3Local(maker.symmetricKey, token, binding.ImplicitAssertion)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, pasetoVerificationError(err)
 	}
 
-	issuedAt, err := parsedToken.GetIssuedAt()
-	if err != nil {
-		return nil, ErrInvalidToken
+	if err := checkFooter(parsedToken.Footer(), binding); err != nil {
+		return nil, err
 	}
 
-	expiredAt, err := parsedToken.GetExpiration()
+	payload, err := payloadFromPasetoToken(parsedToken)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, err
 	}
 
-	payload := &Payload{
-		ID:        id,
-		Username:  username,
-		IssuedAt:  issuedAt,
-		ExpiredAt: expiredAt,
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
 	}
 
 	return payload, nil
@@ -2,88 +2,154 @@ package token
 
 import (
 	"aidanwoods.dev/go-paseto"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
 	"fmt"
-	"github.com/google/uuid"
 	"time"
 )
 
 type PasetoV3Public struct {
-	privateKey paseto.V3AsymmetricSecretKey
+	privateKey *paseto.V3AsymmetricSecretKey // nil for a verifier
 	publicKey  paseto.V3AsymmetricPublicKey
+	keySigner  KeySigner
+	options
 }
 
-func NewPasetoV3Public(privateKeyHex, publicKeyHex string) (*PasetoV3Public, error) {
+func NewPasetoV3Public(privateKeyHex, publicKeyHex string, opts ...Option) (*PasetoV3Public, error) {
 	privateKey, err := paseto.NewV3AsymmetricSecretKeyFromHex(privateKeyHex)
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
 	}
 
+	maker, err := NewPasetoV3PublicVerifier(publicKeyHex, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if privateKey.Public().ExportHex() != maker.publicKey.ExportHex() {
+		return nil, fmt.Errorf("%w: public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
+	maker.privateKey = &privateKey
+	return maker, nil
+}
+
+// NewPasetoV3PublicVerifier initializes a maker that only verifies tokens, with nothing but the public key (in hex format).
+func NewPasetoV3PublicVerifier(publicKeyHex string, opts ...Option) (*PasetoV3Public, error) {
 	publicKey, err := paseto.NewV3AsymmetricPublicKeyFromHex(publicKeyHex)
 	if err != nil {
 		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
 	}
 
 	maker := &PasetoV3Public{
-		privateKey: privateKey,
-		publicKey:  publicKey,
+		publicKey: publicKey,
+		options:   newOptions(opts),
 	}
+	maker.options.usePASERKKeyID(maker.PASERKID)
 
 	return maker, nil
 }
 
-func (maker *PasetoV3Public) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+// NewPasetoV3PublicWithSigner initializes a maker signing through signer, which holds a P-384 key.
+func NewPasetoV3PublicWithSigner(signer KeySigner, opts ...Option) (*PasetoV3Public, error) {
+	publicKey, err := keySignerPublicKey[*ecdsa.PublicKey](signer, "ECDSA")
 	if err != nil {
-		return "", payload, fmt.Errorf("could not initialize payload: %w", err)
+		return nil, err
 	}
 
-	token := paseto.NewToken()
-	token.SetIssuedAt(time.Now())
-	token.SetNotBefore(time.Now())
-	token.SetExpiration(time.Now().Add(duration))
-	token.SetString("username", payload.Username)
-	token.SetString("id", payload.ID.String())
+	paserkPublicKey, err := paseto.NewV3AsymmetricPublicKeyFromEcdsa(*publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
+	}
 
-	signedToken := token.V3Sign(maker.privateKey, nil)
-	return signedToken, payload, nil
+	maker, err := NewPasetoV3PublicVerifier(paserkPublicKey.ExportHex(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	maker.keySigner = signer
+	return maker, nil
 }
 
-func (maker *PasetoV3Public) VerifyToken(token string) (*Payload, error) {
-	parsedToken, err := paseto.NewParser().ParseV3Public(maker.publicKey, token, nil)
+func (maker *PasetoV3Public) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+// CreateTokenWithClaims creates a new token with the given username, duration and custom claims.
+func (maker *PasetoV3Public) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
 	if err != nil {
-		return nil, fmt.Errorf("could not parse payload: %s", err)
+		return "", payload, fmt.Errorf("could not initialize payload: %w", err)
 	}
 
-	idString, err := parsedToken.GetString("id")
+	token, err := maker.CreateTokenFromPayload(payload)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", nil, err
 	}
 
-	id, err := uuid.Parse(idString)
-	if err != nil {
-		return nil, fmt.Errorf("could not parse guid to string: %s", err)
+	return token, payload, nil
+}
+
+// CreateTokenFromPayload creates a new token for a payload built by the caller, filling in the maker's issuer and audience.
+func (maker *PasetoV3Public) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.CreateBoundToken(payload, Binding{})
+}
+
+// CreateBoundToken creates a new token for a payload, bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV3Public) CreateBoundToken(payload *Payload, binding Binding) (string, error) {
+	return maker.createBoundToken(context.Background(), payload, binding)
+}
+
+func (maker *PasetoV3Public) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	return maker.createBoundToken(ctx, payload, Binding{})
+}
+
+func (maker *PasetoV3Public) createBoundToken(ctx context.Context, payload *Payload, binding Binding) (string, error) {
+	if maker.keySigner == nil && maker.privateKey == nil {
+		return "", ErrVerificationOnly
 	}
+	binding = maker.options.binding(binding)
+	maker.options.stamp(payload)
 
-	username, err := parsedToken.GetString("username")
+	token, err := newPasetoToken(payload, maker.options, binding)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return "", err
 	}
 
-	issuedAt, err := parsedToken.GetIssuedAt()
+	if maker.keySigner != nil {
+		message := token.ClaimsJSON()
+		signature, err := signECDSA(ctx, maker.keySigner, crypto.SHA384, 48, pae(maker.publicKey.ExportBytes(), []byte(paseto.V3Public.Header()), message, token.Footer(), binding.ImplicitAssertion))
+		if err != nil {
+			return "", err
+		}
+		return encodePublicPaseto(paseto.V3Public, message, signature, token.Footer()), nil
+	}
+
+	return token.V3Sign(*maker.privateKey, binding.ImplicitAssertion), nil
+}
+
+func (maker *PasetoV3Public) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyBoundToken(token, Binding{})
+}
+
+// VerifyBoundToken verifies a token that must be bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV3Public) VerifyBoundToken(token string, binding Binding) (*Payload, error) {
+	binding = maker.options.binding(binding)
+	parsedToken, err := maker.options.pasetoParser().ParseV3Public(maker.publicKey, token, binding.ImplicitAssertion)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, pasetoVerificationError(err)
+	}
+
+	if err := checkFooter(parsedToken.Footer(), binding); err != nil {
+		return nil, err
 	}
 
-	expiredAt, err := parsedToken.GetExpiration()
+	payload, err := payloadFromPasetoToken(parsedToken)
 	if err != nil {
-		return nil, ErrInvalidToken
+		return nil, err
 	}
 
-	payload := &Payload{
-		ID:        id,
-		Username:  username,
-		IssuedAt:  issuedAt,
-		ExpiredAt: expiredAt,
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
 	}
 
 	return payload, nil
@@ -103,8 +103,12 @@ func TestPasetoV3Public(t *testing.T) {
 		differentPrivateKey := paseto.NewV3AsymmetricSecretKey()
 		differentPublicKey := differentPrivateKey.Public()
 
-		// Create new maker with different public key
-		wrongMaker, err := NewPasetoV3Public(privateKeyHex, differentPublicKey.ExportHex())
+		// A maker can't be built from a mismatched key pair
+		_, err = NewPasetoV3Public(privateKeyHex, differentPublicKey.ExportHex())
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		// Create a verifier with the different public key
+		wrongMaker, err := NewPasetoV3PublicVerifier(differentPublicKey.ExportHex())
 		require.NoError(t, err)
 
 		// Verify should fail because public key doesn't match
@@ -0,0 +1,118 @@
+package token
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+// PasetoV4Local handles PASETO V4 Local tokens (XChaCha20 + BLAKE2b).
+type PasetoV4Local struct {
+	symmetricKey paseto.V4SymmetricKey
+	options
+}
+
+// NewPasetoV4Local initializes a new PASETO V4 Local instance with the given symmetric key (in hex format).
+func NewPasetoV4Local(symmetricKeyHex string, opts ...Option) (*PasetoV4Local, error) {
+	// Decode the hexadecimal symmetric key
+	keyBytes, err := hex.DecodeString(symmetricKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid symmetric key hex")
+	}
+
+	return NewPasetoV4LocalFromBytes(keyBytes, opts...)
+}
+
+// NewPasetoV4LocalFromBytes initializes a new PASETO V4 Local instance with the given raw symmetric key.
+func NewPasetoV4LocalFromBytes(keyBytes []byte, opts ...Option) (*PasetoV4Local, error) {
+	// Ensure the key is exactly 32 bytes, as required by the PASETO V4 specification
+	if len(keyBytes) != 32 {
+		return nil, fmt.Errorf("symmetric key must be 32 bytes long")
+	}
+
+	// Use the key bytes to initialize the symmetric key
+	symmetricKey, err := paseto.V4SymmetricKeyFromBytes(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize symmetric key: %w", err)
+	}
+
+	maker := &PasetoV4Local{
+		symmetricKey: symmetricKey,
+		options:      newOptions(opts),
+	}
+	maker.options.usePASERKKeyID(maker.PASERKID)
+
+	return maker, nil
+}
+
+// CreateToken creates a new PASETO V4 Local token with the given username and duration.
+func (maker *PasetoV4Local) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+// CreateTokenWithClaims creates a new token with the given username, duration and custom claims.
+func (maker *PasetoV4Local) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := maker.CreateTokenFromPayload(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, payload, nil
+}
+
+// CreateTokenFromPayload creates a new token for a payload built by the caller, filling in the maker's issuer and audience.
+func (maker *PasetoV4Local) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.CreateBoundToken(payload, Binding{})
+}
+
+// CreateBoundToken creates a new token for a payload, bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV4Local) CreateBoundToken(payload *Payload, binding Binding) (string, error) {
+	binding = maker.options.binding(binding)
+	maker.options.stamp(payload)
+
+	token, err := newPasetoToken(payload, maker.options, binding)
+	if err != nil {
+		return "", err
+	}
+
+	// Encrypt the token using the symmetric key
+	return token.V4Encrypt(maker.symmetricKey, binding.ImplicitAssertion), nil
+}
+
+// VerifyToken verifies a given PASETO V4 Local token and returns the payload if valid.
+func (maker *PasetoV4Local) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyBoundToken(token, Binding{})
+}
+
+// VerifyBoundToken verifies a token that must be bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV4Local) VerifyBoundToken(token string, binding Binding) (*Payload, error) {
+	binding = maker.options.binding(binding)
+
+	// Parse the encrypted token
+	parsedToken, err := maker.options.pasetoParser().ParseV4Local(maker.symmetricKey, token, binding.ImplicitAssertion)
+	if err != nil {
+		return nil, pasetoVerificationError(err)
+	}
+
+	if err := checkFooter(parsedToken.Footer(), binding); err != nil {
+		return nil, err
+	}
+
+	payload, err := payloadFromPasetoToken(parsedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,180 @@
+package token
+
+import (
+	"aidanwoods.dev/go-paseto"
+	"context"
+	"fmt"
+	"golang.org/x/crypto/ed25519"
+	"time"
+)
+
+// PasetoV4Public handles PASETO V4 Public tokens (Ed25519).
+type PasetoV4Public struct {
+	privateKey *paseto.V4AsymmetricSecretKey // nil for a verifier
+	publicKey  paseto.V4AsymmetricPublicKey
+	keySigner  KeySigner
+	options
+}
+
+// NewPasetoV4Public initializes a new PASETO V4 Public instance with the given key pair (in hex format).
+func NewPasetoV4Public(privateKeyHex, publicKeyHex string, opts ...Option) (*PasetoV4Public, error) {
+	privateKey, err := paseto.NewV4AsymmetricSecretKeyFromHex(privateKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
+	}
+
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromHex(publicKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
+	}
+
+	return newPasetoV4Public(&privateKey, publicKey, opts)
+}
+
+// NewPasetoV4PublicFromBytes initializes a new PASETO V4 Public instance with the given raw key pair.
+func NewPasetoV4PublicFromBytes(privateKeyBytes, publicKeyBytes []byte, opts ...Option) (*PasetoV4Public, error) {
+	privateKey, err := paseto.NewV4AsymmetricSecretKeyFromBytes(privateKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize private asymmetric key: %w", err)
+	}
+
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromBytes(publicKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
+	}
+
+	return newPasetoV4Public(&privateKey, publicKey, opts)
+}
+
+// NewPasetoV4PublicVerifier initializes a maker that only verifies tokens, with nothing but the public key (in hex format).
+func NewPasetoV4PublicVerifier(publicKeyHex string, opts ...Option) (*PasetoV4Public, error) {
+	publicKey, err := paseto.NewV4AsymmetricPublicKeyFromHex(publicKeyHex)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
+	}
+
+	return newPasetoV4Public(nil, publicKey, opts)
+}
+
+// NewPasetoV4PublicWithSigner initializes a maker signing through signer, which holds an Ed25519 key.
+func NewPasetoV4PublicWithSigner(signer KeySigner, opts ...Option) (*PasetoV4Public, error) {
+	publicKey, err := keySignerPublicKey[ed25519.PublicKey](signer, "Ed25519")
+	if err != nil {
+		return nil, err
+	}
+
+	paserkPublicKey, err := paseto.NewV4AsymmetricPublicKeyFromEd25519(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize public asymmetric key: %w", err)
+	}
+
+	maker, err := newPasetoV4Public(nil, paserkPublicKey, opts)
+	if err != nil {
+		return nil, err
+	}
+	maker.keySigner = signer
+	return maker, nil
+}
+
+// newPasetoV4Public creates a maker for a key pair, or a verifier when privateKey is nil
+func newPasetoV4Public(privateKey *paseto.V4AsymmetricSecretKey, publicKey paseto.V4AsymmetricPublicKey, opts []Option) (*PasetoV4Public, error) {
+	if privateKey != nil && privateKey.Public().ExportHex() != publicKey.ExportHex() {
+		return nil, fmt.Errorf("%w: public key doesn't belong to the private key", ErrKeyMismatch)
+	}
+
+	maker := &PasetoV4Public{
+		privateKey: privateKey,
+		publicKey:  publicKey,
+		options:    newOptions(opts),
+	}
+	maker.options.usePASERKKeyID(maker.PASERKID)
+
+	return maker, nil
+}
+
+// CreateToken creates a new signed PASETO V4 Public token with the given username and duration.
+func (maker *PasetoV4Public) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.CreateTokenWithClaims(username, duration, nil)
+}
+
+// CreateTokenWithClaims creates a new token with the given username, duration and custom claims.
+func (maker *PasetoV4Public) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	payload, err := newPayloadAt(username, duration, claims, maker.options.now())
+	if err != nil {
+		return "", payload, fmt.Errorf("could not initialize payload: %w", err)
+	}
+
+	token, err := maker.CreateTokenFromPayload(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, payload, nil
+}
+
+// CreateTokenFromPayload creates a new token for a payload built by the caller, filling in the maker's issuer and audience.
+func (maker *PasetoV4Public) CreateTokenFromPayload(payload *Payload) (string, error) {
+	return maker.CreateBoundToken(payload, Binding{})
+}
+
+// CreateBoundToken creates a new token for a payload, bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV4Public) CreateBoundToken(payload *Payload, binding Binding) (string, error) {
+	return maker.createBoundToken(context.Background(), payload, binding)
+}
+
+func (maker *PasetoV4Public) createTokenFromPayload(ctx context.Context, payload *Payload) (string, error) {
+	return maker.createBoundToken(ctx, payload, Binding{})
+}
+
+func (maker *PasetoV4Public) createBoundToken(ctx context.Context, payload *Payload, binding Binding) (string, error) {
+	if maker.keySigner == nil && maker.privateKey == nil {
+		return "", ErrVerificationOnly
+	}
+	binding = maker.options.binding(binding)
+	maker.options.stamp(payload)
+
+	token, err := newPasetoToken(payload, maker.options, binding)
+	if err != nil {
+		return "", err
+	}
+
+	if maker.keySigner != nil {
+		message := token.ClaimsJSON()
+		signature, err := signEd25519(ctx, maker.keySigner, pae([]byte(paseto.V4Public.Header()), message, token.Footer(), binding.ImplicitAssertion))
+		if err != nil {
+			return "", err
+		}
+		return encodePublicPaseto(paseto.V4Public, message, signature, token.Footer()), nil
+	}
+
+	return token.V4Sign(*maker.privateKey, binding.ImplicitAssertion), nil
+}
+
+// VerifyToken verifies the signature of a given PASETO V4 Public token and returns the payload if valid.
+func (maker *PasetoV4Public) VerifyToken(token string) (*Payload, error) {
+	return maker.VerifyBoundToken(token, Binding{})
+}
+
+// VerifyBoundToken verifies a token that must be bound to the maker's footer and implicit assertion merged with binding.
+func (maker *PasetoV4Public) VerifyBoundToken(token string, binding Binding) (*Payload, error) {
+	binding = maker.options.binding(binding)
+	parsedToken, err := maker.options.pasetoParser().ParseV4Public(maker.publicKey, token, binding.ImplicitAssertion)
+	if err != nil {
+		return nil, pasetoVerificationError(err)
+	}
+
+	if err := checkFooter(parsedToken.Footer(), binding); err != nil {
+		return nil, err
+	}
+
+	payload, err := payloadFromPasetoToken(parsedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := maker.options.verify(payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
@@ -1,7 +1,9 @@
 package token
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"time"
 )
@@ -10,17 +12,66 @@ import (
 var (
 	ErrInvalidToken = errors.New("token is invalid")
 	ErrExpiredToken = errors.New("could not parse payload: this token has expired")
+
+	// ErrTokenNotYetValid is returned for a token whose not-before time hasn't been reached
+	ErrTokenNotYetValid = fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
 )
 
 // Payload will hold payload data of token
 type Payload struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	IssuedAt  time.Time `json:"issued_at"`
-	ExpiredAt time.Time `json:"expired_at"`
+	ID        uuid.UUID
+	Username  string
+	Issuer    string
+	Subject   string
+	Audience  Audience
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiredAt time.Time
+
+	// Scopes and Roles hold the permissions granted to the token, see RequireScopes and RequireAnyRole
+	Scopes Scopes
+	Roles  []string
+
+	// Claims holds the custom claims carried alongside the standard ones, keyed by claim name
+	Claims map[string]json.RawMessage
+}
+
+// payloadClaims is the JSON form of a Payload, using the RFC 7519 registered claim names
+type payloadClaims struct {
+	ID        string       `json:"jti"`
+	Username  string       `json:"username"`
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	IssuedAt  numericDate  `json:"iat"`
+	NotBefore *numericDate `json:"nbf,omitempty"`
+	ExpiredAt numericDate  `json:"exp"`
+	Scopes    Scopes       `json:"scope,omitempty"`
+	Roles     roles        `json:"roles,omitempty"`
+
+	// Tokens issued before the registered claim names were adopted used these instead
+	LegacyID        string     `json:"id,omitempty"`
+	LegacyIssuedAt  *time.Time `json:"issued_at,omitempty"`
+	LegacyExpiredAt *time.Time `json:"expired_at,omitempty"`
 }
 
 func NewPayload(username string, duration time.Duration) (*Payload, error) {
+	return NewPayloadWithClaims(username, duration, nil)
+}
+
+// NewPayloadWithClaims creates a payload that also carries the given custom claims.
+// claims must encode to a JSON object whose keys don't collide with the standard claims.
+func NewPayloadWithClaims(username string, duration time.Duration, claims interface{}) (*Payload, error) {
+	return newPayloadAt(username, duration, claims, time.Now())
+}
+
+// newPayloadAt creates a payload issued at now, so makers can stamp it with their own clock
+func newPayloadAt(username string, duration time.Duration, claims interface{}, now time.Time) (*Payload, error) {
+	customClaims, err := encodeClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -29,8 +80,10 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenID,
 		Username:  username,
-		IssuedAt:  time.Now(),
-		ExpiredAt: time.Now().Add(duration),
+		IssuedAt:  now,
+		NotBefore: now,
+		ExpiredAt: now.Add(duration),
+		Claims:    customClaims,
 	}
 
 	return payload, nil
@@ -39,8 +92,110 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 
 // Valid checks if the payload is valid or not
 func (payload *Payload) Valid() error {
-	if time.Now().After(payload.ExpiredAt) {
-		return ErrExpiredToken
+	return payload.ValidAt(time.Now(), 0)
+}
+
+// ValidAt checks if the payload is valid at the given time, tolerating up to leeway of clock skew
+// on both its expiry and not-before times
+func (payload *Payload) ValidAt(now time.Time, leeway time.Duration) error {
+	if now.After(payload.ExpiredAt.Add(leeway)) {
+		return newVerificationError(ReasonExpired, nil)
+	}
+	if now.Before(payload.NotBefore.Add(-leeway)) {
+		return newVerificationError(ReasonNotYetValid, nil)
+	}
+	return nil
+}
+
+// DecodeClaims decodes the payload's custom claims into v
+func (payload *Payload) DecodeClaims(v interface{}) error {
+	if len(payload.Claims) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(payload.Claims)
+	if err != nil {
+		return err
 	}
+	return json.Unmarshal(data, v)
+}
+
+// MarshalJSON encodes the payload as a flat set of claims, custom claims included
+func (payload Payload) MarshalJSON() ([]byte, error) {
+	claims := payloadClaims{
+		ID:        payload.ID.String(),
+		Username:  payload.Username,
+		Issuer:    payload.Issuer,
+		Subject:   payload.Subject,
+		Audience:  payload.Audience,
+		IssuedAt:  numericDate(payload.IssuedAt),
+		ExpiredAt: numericDate(payload.ExpiredAt),
+		Scopes:    payload.Scopes,
+		Roles:     payload.Roles,
+	}
+	if !payload.NotBefore.IsZero() {
+		notBefore := numericDate(payload.NotBefore)
+		claims.NotBefore = &notBefore
+	}
+
+	standard, err := json.Marshal(claims)
+	if err != nil || len(payload.Claims) == 0 {
+		return standard, err
+	}
+
+	merged := make(map[string]json.RawMessage, len(payload.Claims))
+	for name, value := range payload.Claims {
+		merged[name] = value
+	}
+	// standard claims always win over custom claims with the same name
+	if err := json.Unmarshal(standard, &merged); err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON reads the standard claims and collects every other claim into Claims
+func (payload *Payload) UnmarshalJSON(data []byte) error {
+	var standard payloadClaims
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	idString := standard.ID
+	if idString == "" {
+		idString = standard.LegacyID
+	}
+	id, err := uuid.Parse(idString)
+	if err != nil {
+		return err
+	}
+
+	*payload = Payload{
+		ID:        id,
+		Username:  standard.Username,
+		Issuer:    standard.Issuer,
+		Subject:   standard.Subject,
+		Audience:  standard.Audience,
+		IssuedAt:  time.Time(standard.IssuedAt),
+		ExpiredAt: time.Time(standard.ExpiredAt),
+		Scopes:    standard.Scopes,
+		Roles:     standard.Roles,
+		Claims:    customClaimsFrom(all),
+	}
+	if standard.NotBefore != nil {
+		payload.NotBefore = time.Time(*standard.NotBefore)
+	}
+	if standard.LegacyIssuedAt != nil {
+		payload.IssuedAt = *standard.LegacyIssuedAt
+	}
+	if standard.LegacyExpiredAt != nil {
+		payload.ExpiredAt = *standard.LegacyExpiredAt
+	}
+
 	return nil
 }
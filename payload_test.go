@@ -1,6 +1,7 @@
 package token
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -58,4 +59,23 @@ func TestPayload(t *testing.T) {
 			})
 		}
 	})
-} 
\ No newline at end of file
+
+	t.Run("JSONWithClaims", func(t *testing.T) {
+		payload, err := NewPayloadWithClaims("user", time.Minute, map[string]interface{}{"email": "user@example.com"})
+		require.NoError(t, err)
+
+		data, err := json.Marshal(payload)
+		require.NoError(t, err)
+
+		// Custom claims sit next to the standard ones
+		var flat map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &flat))
+		require.Equal(t, "user@example.com", flat["email"])
+		require.Equal(t, "user", flat["username"])
+
+		var decoded Payload
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, payload.ID, decoded.ID)
+		require.Equal(t, payload.Claims, decoded.Claims)
+	})
+}
@@ -0,0 +1,64 @@
+package token
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+)
+
+// ParsePEMPrivateKey parses a PEM encoded PKCS #8, EC or PKCS #1 RSA private key
+func ParsePEMPrivateKey(data []byte) (crypto.Signer, error) {
+	key, err := parsePEMKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("PEM key of type %T is not a private key", key)
+	}
+	return signer, nil
+}
+
+// ParsePEMPublicKey parses a PEM encoded PKIX or PKCS #1 RSA public key
+func ParsePEMPublicKey(data []byte) (crypto.PublicKey, error) {
+	key, err := parsePEMKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := key.(crypto.Signer); ok {
+		return nil, fmt.Errorf("PEM key of type %T is not a public key", key)
+	}
+	return key, nil
+}
+
+// parsePEMKey parses the first PEM block of data into the private or public key it holds
+func parsePEMKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM key")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid PEM key: %w", err)
+	}
+	return key, nil
+}
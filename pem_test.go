@@ -0,0 +1,65 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestParsePEMKey(t *testing.T) {
+	_, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey := testRSAKey()
+
+	encode := func(blockType string) func(der []byte, err error) []byte {
+		return func(der []byte, err error) []byte {
+			require.NoError(t, err)
+			return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+		}
+	}
+
+	t.Run("PrivateKeys", func(t *testing.T) {
+		for name, data := range map[string][]byte{
+			"PKCS8":    []byte(pemPrivateKey(t, edPrivateKey)),
+			"EC":       encode("EC PRIVATE KEY")(x509.MarshalECPrivateKey(ecKey)),
+			"PKCS1RSA": encode("RSA PRIVATE KEY")(x509.MarshalPKCS1PrivateKey(rsaKey), nil),
+		} {
+			signer, err := ParsePEMPrivateKey(data)
+			require.NoError(t, err, name)
+			require.NotNil(t, signer.Public(), name)
+		}
+
+		_, err := ParsePEMPrivateKey(encode("PUBLIC KEY")(x509.MarshalPKIXPublicKey(&ecKey.PublicKey)))
+		require.Error(t, err)
+	})
+
+	t.Run("PublicKeys", func(t *testing.T) {
+		publicKey, err := ParsePEMPublicKey(encode("PUBLIC KEY")(x509.MarshalPKIXPublicKey(&ecKey.PublicKey)))
+		require.NoError(t, err)
+		require.True(t, ecKey.PublicKey.Equal(publicKey))
+
+		publicKey, err = ParsePEMPublicKey(encode("RSA PUBLIC KEY")(x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey), nil))
+		require.NoError(t, err)
+		require.True(t, rsaKey.PublicKey.Equal(publicKey))
+
+		_, err = ParsePEMPublicKey([]byte(pemPrivateKey(t, ecKey)))
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := ParsePEMPrivateKey([]byte("not PEM"))
+		require.Error(t, err)
+		_, err = ParsePEMPrivateKey(encode("CERTIFICATE")([]byte("abc"), nil))
+		require.ErrorContains(t, err, "unsupported PEM block")
+		_, err = ParsePEMPrivateKey(encode("PRIVATE KEY")([]byte("abc"), nil))
+		require.Error(t, err)
+	})
+}
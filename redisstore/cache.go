@@ -0,0 +1,122 @@
+package redisstore
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// negativeCache is an LRU of the token IDs recently found not to be revoked, each remembered for ttl.
+// Revoking an ID leaves a tombstone in its place, so a lookup that started before the revocation can't cache it
+// as not revoked once it completes
+type negativeCache struct {
+	mu       sync.Mutex
+	size     int
+	ttl      time.Duration
+	order    *list.List // of cacheEntry, most recently used first
+	entries  map[uuid.UUID]*list.Element
+	revision uint64 // bumped on every revocation
+	dropped  uint64 // the latest revision of a tombstone no longer in the cache
+}
+
+type cacheEntry struct {
+	id        uuid.UUID
+	expiresAt time.Time
+
+	// set on tombstones, the revision of the revocation
+	revision uint64
+}
+
+func newNegativeCache(size int, ttl time.Duration) *negativeCache {
+	return &negativeCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[uuid.UUID]*list.Element, size),
+	}
+}
+
+// contains reports whether id was recently found not to be revoked
+func (cache *negativeCache) contains(id uuid.UUID, now time.Time) bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.entries[id]
+	if !ok {
+		return false
+	}
+	entry := element.Value.(cacheEntry)
+	if now.After(entry.expiresAt) {
+		cache.drop(element)
+		return false
+	}
+	if entry.revision > 0 {
+		return false
+	}
+	cache.order.MoveToFront(element)
+	return true
+}
+
+// begin returns the revision a lookup starts at, to be passed to add once it completes
+func (cache *negativeCache) begin() uint64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.revision
+}
+
+// add remembers that a lookup started at revision since found id not to be revoked, unless id has been revoked
+// since. The least recently used entry is evicted when the cache is full
+func (cache *negativeCache) add(id uuid.UUID, now time.Time, since uint64) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.dropped > since {
+		// the tombstone of a revocation made since is gone, id may be that one
+		return
+	}
+	if element, ok := cache.entries[id]; ok && element.Value.(cacheEntry).revision > since {
+		return
+	}
+	cache.put(cacheEntry{id: id, expiresAt: now.Add(cache.ttl)})
+}
+
+// remove forgets id and leaves a tombstone in its place, it has just been revoked
+func (cache *negativeCache) remove(id uuid.UUID, now time.Time) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.revision++
+	cache.put(cacheEntry{id: id, expiresAt: now.Add(cache.ttl), revision: cache.revision})
+}
+
+// put stores entry as the most recently used one, the caller must hold the lock
+func (cache *negativeCache) put(entry cacheEntry) {
+	if element, ok := cache.entries[entry.id]; ok {
+		element.Value = entry
+		cache.order.MoveToFront(element)
+		return
+	}
+
+	cache.entries[entry.id] = cache.order.PushFront(entry)
+	if cache.order.Len() > cache.size {
+		cache.drop(cache.order.Back())
+	}
+}
+
+// drop removes an entry, the caller must hold the lock
+func (cache *negativeCache) drop(element *list.Element) {
+	entry := element.Value.(cacheEntry)
+	cache.order.Remove(element)
+	delete(cache.entries, entry.id)
+	if entry.revision > cache.dropped {
+		cache.dropped = entry.revision
+	}
+}
+
+func (cache *negativeCache) len() int {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.order.Len()
+}
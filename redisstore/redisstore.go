@@ -0,0 +1,176 @@
+// Package redisstore keeps revoked token IDs in Redis, so a revocation made on one replica is seen by all of them
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fsobh/token"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// defaultKeyPrefix is prepended to the token IDs to form their Redis keys
+	defaultKeyPrefix = "token:revoked:"
+
+	// defaultCacheSize and defaultCacheTTL bound the local cache of tokens known not to be revoked
+	defaultCacheSize = 10000
+	defaultCacheTTL  = 5 * time.Second
+)
+
+// Option configures a RevocationStore
+type Option func(*RevocationStore)
+
+// WithKeyPrefix sets the prefix of the store's Redis keys, "token:revoked:" by default
+func WithKeyPrefix(prefix string) Option {
+	return func(store *RevocationStore) {
+		store.prefix = prefix
+	}
+}
+
+// WithNegativeCache sets the size of the local cache of token IDs found not to be revoked, and how long each is
+// trusted. A revocation made on another replica is only seen here once the entry is gone, so ttl is how stale a
+// lookup may be. 10000 entries for 5 seconds by default, a size or ttl of 0 turns the cache off
+func WithNegativeCache(size int, ttl time.Duration) Option {
+	return func(store *RevocationStore) {
+		store.cacheSize = size
+		store.cacheTTL = ttl
+	}
+}
+
+// WithLeeway keeps revocations for leeway past the expiry of their token. Set it to the leeway the makers
+// verify with (see token.WithLeeway), or a revoked token would be accepted again during that grace period.
+// Not needed behind a token.RevocableMaker, which adds its maker's leeway to the expiry it revokes until
+func WithLeeway(leeway time.Duration) Option {
+	return func(store *RevocationStore) {
+		store.leeway = leeway
+	}
+}
+
+// WithClock makes the store read the current time from clock instead of the system clock
+func WithClock(clock token.Clock) Option {
+	return func(store *RevocationStore) {
+		store.clock = clock
+	}
+}
+
+// RevocationStore is a token.RevocationStore kept in Redis. Every revoked token ID is a key that expires with its
+// token, so Redis drops revocations once they no longer matter. Lookups of tokens that aren't revoked, by far the
+// most common ones, are answered from a local LRU cache for a few seconds, see WithNegativeCache
+type RevocationStore struct {
+	client    redis.UniversalClient
+	prefix    string
+	leeway    time.Duration
+	clock     token.Clock
+	cacheSize int
+	cacheTTL  time.Duration
+	cache     *negativeCache
+}
+
+var _ token.RevocationStore = (*RevocationStore)(nil)
+
+// NewRevocationStore creates a store keeping its revocations in the Redis server, cluster or sentinel setup of client
+func NewRevocationStore(client redis.UniversalClient, opts ...Option) (*RevocationStore, error) {
+	if client == nil {
+		return nil, fmt.Errorf("Redis client is required")
+	}
+
+	store := &RevocationStore{
+		client:    client,
+		prefix:    defaultKeyPrefix,
+		cacheSize: defaultCacheSize,
+		cacheTTL:  defaultCacheTTL,
+	}
+	for _, opt := range opts {
+		opt(store)
+	}
+	if store.leeway < 0 {
+		return nil, fmt.Errorf("leeway must not be negative")
+	}
+	if store.cacheSize > 0 && store.cacheTTL > 0 {
+		store.cache = newNegativeCache(store.cacheSize, store.cacheTTL)
+	}
+
+	return store, nil
+}
+
+// Revoke denylists a token ID until expiresAt, plus the store's leeway
+func (store *RevocationStore) Revoke(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	ttl := expiresAt.Add(store.leeway).Sub(store.now())
+	if ttl <= 0 {
+		// the token is expired anyway
+		return nil
+	}
+
+	if err := store.client.Set(ctx, store.key(id), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("could not revoke token: %w", err)
+	}
+
+	// this replica sees its own revocations at once, including the lookups already in flight
+	if store.cache != nil {
+		store.cache.remove(id, store.now())
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token ID has been revoked
+func (store *RevocationStore) IsRevoked(ctx context.Context, id uuid.UUID) (bool, error) {
+	revoked, err := store.AreRevoked(ctx, []uuid.UUID{id})
+	if err != nil {
+		return false, err
+	}
+	return revoked[0], nil
+}
+
+// AreRevoked reports for each of ids whether it has been revoked. The IDs missing from the local cache are looked
+// up in a single pipelined round trip
+func (store *RevocationStore) AreRevoked(ctx context.Context, ids []uuid.UUID) ([]bool, error) {
+	revoked := make([]bool, len(ids))
+	now := store.now()
+	var since uint64
+	if store.cache != nil {
+		since = store.cache.begin()
+	}
+
+	var lookups []int
+	for i, id := range ids {
+		if store.cache == nil || !store.cache.contains(id, now) {
+			lookups = append(lookups, i)
+		}
+	}
+	if len(lookups) == 0 {
+		return revoked, nil
+	}
+
+	results := make([]*redis.IntCmd, len(lookups))
+	_, err := store.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for j, i := range lookups {
+			results[j] = pipe.Exists(ctx, store.key(ids[i]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not look up revoked tokens: %w", err)
+	}
+
+	for j, i := range lookups {
+		revoked[i] = results[j].Val() > 0
+		if !revoked[i] && store.cache != nil {
+			store.cache.add(ids[i], now, since)
+		}
+	}
+	return revoked, nil
+}
+
+func (store *RevocationStore) key(id uuid.UUID) string {
+	return store.prefix + id.String()
+}
+
+func (store *RevocationStore) now() time.Time {
+	if store.clock != nil {
+		return store.clock.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,269 @@
+package redisstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fsobh/token"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+	return server, client
+}
+
+func TestRevocationStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Revoke", func(t *testing.T) {
+		server, client := newTestRedis(t)
+		store, err := NewRevocationStore(client, WithLeeway(30*time.Second))
+		require.NoError(t, err)
+
+		id := uuid.New()
+		revoked, err := store.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.False(t, revoked)
+
+		require.NoError(t, store.Revoke(ctx, id, time.Now().Add(time.Minute)))
+		revoked, err = store.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.True(t, revoked)
+
+		// the key expires with the token, plus the leeway
+		ttl := server.TTL("token:revoked:" + id.String())
+		require.InDelta(t, 90*time.Second, ttl, float64(time.Second))
+
+		server.FastForward(91 * time.Second)
+		revoked, err = store.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.False(t, revoked)
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		server, client := newTestRedis(t)
+		store, err := NewRevocationStore(client)
+		require.NoError(t, err)
+
+		require.NoError(t, store.Revoke(ctx, uuid.New(), time.Now().Add(-time.Minute)))
+		require.Empty(t, server.Keys())
+	})
+
+	t.Run("NegativeCache", func(t *testing.T) {
+		server, client := newTestRedis(t)
+		now := time.Now()
+		clock := token.ClockFunc(func() time.Time { return now })
+
+		replica1, err := NewRevocationStore(client, WithClock(clock), WithNegativeCache(100, 5*time.Second))
+		require.NoError(t, err)
+		replica2, err := NewRevocationStore(client, WithClock(clock))
+		require.NoError(t, err)
+
+		id := uuid.New()
+		revoked, err := replica1.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.False(t, revoked)
+
+		// answered from the cache, without a round trip
+		commands := server.CommandCount()
+		revoked, err = replica1.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.False(t, revoked)
+		require.Equal(t, commands, server.CommandCount())
+
+		// another replica's revocation is seen once the cache entry expires
+		require.NoError(t, replica2.Revoke(ctx, id, now.Add(time.Hour)))
+		revoked, err = replica1.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.False(t, revoked)
+
+		now = now.Add(6 * time.Second)
+		revoked, err = replica1.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.True(t, revoked)
+
+		// a replica's own revocation is seen at once
+		other := uuid.New()
+		_, err = replica1.IsRevoked(ctx, other)
+		require.NoError(t, err)
+		require.NoError(t, replica1.Revoke(ctx, other, now.Add(time.Hour)))
+		revoked, err = replica1.IsRevoked(ctx, other)
+		require.NoError(t, err)
+		require.True(t, revoked)
+	})
+
+	t.Run("AreRevoked", func(t *testing.T) {
+		server, client := newTestRedis(t)
+		store, err := NewRevocationStore(client)
+		require.NoError(t, err)
+
+		ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+		require.NoError(t, store.Revoke(ctx, ids[1], time.Now().Add(time.Hour)))
+		require.NoError(t, store.Revoke(ctx, ids[3], time.Now().Add(time.Hour)))
+
+		revoked, err := store.AreRevoked(ctx, ids)
+		require.NoError(t, err)
+		require.Equal(t, []bool{false, true, false, true}, revoked)
+
+		// only the revoked IDs are looked up again
+		commands := server.CommandCount()
+		revoked, err = store.AreRevoked(ctx, ids)
+		require.NoError(t, err)
+		require.Equal(t, []bool{false, true, false, true}, revoked)
+		require.Equal(t, commands+2, server.CommandCount())
+
+		revoked, err = store.AreRevoked(ctx, nil)
+		require.NoError(t, err)
+		require.Empty(t, revoked)
+	})
+
+	t.Run("RedisDown", func(t *testing.T) {
+		server, client := newTestRedis(t)
+		store, err := NewRevocationStore(client, WithNegativeCache(0, 0))
+		require.NoError(t, err)
+		server.Close()
+
+		_, err = store.IsRevoked(ctx, uuid.New())
+		require.Error(t, err)
+		require.Error(t, store.Revoke(ctx, uuid.New(), time.Now().Add(time.Hour)))
+	})
+
+	t.Run("RevocableMaker", func(t *testing.T) {
+		_, client := newTestRedis(t)
+		store, err := NewRevocationStore(client, WithKeyPrefix("myapp:revoked:"))
+		require.NoError(t, err)
+
+		maker, err := token.NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+
+		// two replicas sharing the store
+		replica1 := token.NewRevocableMaker(maker, store)
+		replica2 := token.NewRevocableMaker(maker, store)
+
+		signed, _, err := replica1.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, replica1.RevokeTokenContext(ctx, signed))
+
+		_, err = replica2.VerifyTokenContext(ctx, signed)
+		require.ErrorIs(t, err, token.ErrRevokedToken)
+		require.Equal(t, int64(1), client.Exists(ctx, "myapp:revoked:"+mustPayloadID(t, maker, signed)).Val())
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := NewRevocationStore(nil)
+		require.Error(t, err)
+
+		_, client := newTestRedis(t)
+		_, err = NewRevocationStore(client, WithLeeway(-time.Second))
+		require.Error(t, err)
+	})
+}
+
+func TestNegativeCache(t *testing.T) {
+	now := time.Now()
+
+	t.Run("LRU", func(t *testing.T) {
+		cache := newNegativeCache(2, time.Second)
+		a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+		cache.add(a, now, cache.begin())
+		cache.add(b, now, cache.begin())
+		require.True(t, cache.contains(a, now))
+
+		// b is the least recently used
+		cache.add(c, now, cache.begin())
+		require.Equal(t, 2, cache.len())
+		require.True(t, cache.contains(a, now))
+		require.False(t, cache.contains(b, now))
+		require.True(t, cache.contains(c, now))
+
+		require.False(t, cache.contains(a, now.Add(2*time.Second)))
+		require.Equal(t, 1, cache.len())
+
+		cache.remove(c, now)
+		require.False(t, cache.contains(c, now))
+	})
+
+	t.Run("Tombstones", func(t *testing.T) {
+		cache := newNegativeCache(2, time.Second)
+		a, b, c := uuid.New(), uuid.New(), uuid.New()
+
+		// a lookup started before the revocation completes after it
+		since := cache.begin()
+		cache.remove(a, now)
+		cache.add(a, now, since)
+		require.False(t, cache.contains(a, now))
+
+		// lookups started after the revocation are cached
+		cache.add(a, now, cache.begin())
+		require.True(t, cache.contains(a, now))
+
+		// and so are the ones of other IDs
+		since = cache.begin()
+		cache.remove(b, now)
+		cache.add(c, now, since)
+		require.True(t, cache.contains(c, now))
+
+		// once b's tombstone is evicted, lookups older than it can't be told apart
+		cache.add(a, now, cache.begin())
+		cache.add(uuid.New(), now, cache.begin())
+		cache.add(b, now, since)
+		require.False(t, cache.contains(b, now))
+	})
+}
+
+func TestRevocationStoreConcurrentRevoke(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	store, err := NewRevocationStore(client, WithNegativeCache(100, time.Minute))
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		id := uuid.New()
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for j := 0; j < 4; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					_, err := store.IsRevoked(ctx, id)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, store.Revoke(ctx, id, time.Now().Add(time.Hour)))
+		close(stop)
+		wg.Wait()
+
+		// the lookups in flight during the revocation didn't cache id as not revoked
+		revoked, err := store.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.True(t, revoked)
+	}
+}
+
+func mustPayloadID(t *testing.T, maker token.Maker, signed string) string {
+	payload, err := maker.VerifyToken(signed)
+	require.NoError(t, err)
+	return payload.ID.String()
+}
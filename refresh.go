@@ -0,0 +1,264 @@
+package token
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Errors returned while exchanging refresh tokens
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenReused   = fmt.Errorf("%w: refresh token reuse detected, its token family has been revoked", ErrInvalidToken)
+)
+
+// refreshTokenSize is the number of random bytes in a refresh token
+const refreshTokenSize = 32
+
+// RefreshToken is the stored state of one refresh token. Only a hash of the token itself is kept
+type RefreshToken struct {
+	Hash      string
+	FamilyID  uuid.UUID
+	Username  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+
+	// Used is set once the token has been exchanged, presenting it again is a reuse
+	Used bool
+	// Revoked is set when the token's family has been revoked
+	Revoked bool
+}
+
+// RefreshTokenStore persists refresh tokens for a RefreshManager
+type RefreshTokenStore interface {
+
+	// Save stores a newly issued refresh token
+	Save(ctx context.Context, token RefreshToken) error
+
+	// Use atomically marks the refresh token with the given hash as used, and returns it as it was
+	// before. Returns ErrRefreshTokenNotFound if there is no such token
+	Use(ctx context.Context, hash string) (*RefreshToken, error)
+
+	// RevokeFamily revokes every refresh token of a token family
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+}
+
+// TokenPair is a short-lived access token and the refresh token that can be exchanged for the next pair
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessPayload    *Payload  `json:"-"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// RefreshManager issues access/refresh token pairs. Access tokens come from a Maker, refresh tokens
+// are opaque random strings tracked in a RefreshTokenStore. Every exchange rotates the refresh token,
+// and presenting an already used refresh token revokes every refresh token of its family (reuse detection).
+// The access tokens already issued to the family aren't revoked, they stay valid until they expire, so keep
+// accessDuration short
+type RefreshManager struct {
+	maker           Maker
+	store           RefreshTokenStore
+	accessDuration  time.Duration
+	refreshDuration time.Duration
+}
+
+// NewRefreshManager creates a manager issuing access tokens valid for accessDuration and refresh tokens
+// valid for refreshDuration
+func NewRefreshManager(maker Maker, store RefreshTokenStore, accessDuration, refreshDuration time.Duration) (*RefreshManager, error) {
+	if accessDuration <= 0 || refreshDuration <= 0 {
+		return nil, fmt.Errorf("token durations must be positive")
+	}
+	if refreshDuration <= accessDuration {
+		return nil, fmt.Errorf("refresh tokens must outlive access tokens")
+	}
+
+	return &RefreshManager{
+		maker:           maker,
+		store:           store,
+		accessDuration:  accessDuration,
+		refreshDuration: refreshDuration,
+	}, nil
+}
+
+// Issue starts a new token family for username, e.g. on login
+func (manager *RefreshManager) Issue(ctx context.Context, username string) (*TokenPair, error) {
+	familyID, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+	return manager.issue(ctx, username, familyID)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented refresh token can't be used again,
+// presenting it anyway revokes the refresh tokens of its family but not the access tokens issued with them
+func (manager *RefreshManager) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := manager.store.Use(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not load refresh token: %w", err)
+	}
+
+	if stored.Revoked {
+		return nil, ErrRevokedToken
+	}
+
+	if stored.Used {
+		if err := manager.store.RevokeFamily(ctx, stored.FamilyID); err != nil {
+			return nil, fmt.Errorf("could not revoke token family: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, ErrExpiredToken
+	}
+
+	return manager.issue(ctx, stored.Username, stored.FamilyID)
+}
+
+// Revoke revokes the refresh tokens of a family, e.g. on logout. Its access tokens stay valid until they expire
+func (manager *RefreshManager) Revoke(ctx context.Context, refreshToken string) error {
+	stored, err := manager.store.Use(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, ErrRefreshTokenNotFound) {
+		return ErrInvalidToken
+	}
+	if err != nil {
+		return fmt.Errorf("could not load refresh token: %w", err)
+	}
+
+	return manager.store.RevokeFamily(ctx, stored.FamilyID)
+}
+
+func (manager *RefreshManager) issue(ctx context.Context, username string, familyID uuid.UUID) (*TokenPair, error) {
+	accessToken, accessPayload, err := AsContextMaker(manager.maker).CreateTokenContext(ctx, username, manager.accessDuration)
+	if err != nil {
+		return nil, fmt.Errorf("could not create access token: %w", err)
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := RefreshToken{
+		Hash:      hashRefreshToken(refreshToken),
+		FamilyID:  familyID,
+		Username:  username,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(manager.refreshDuration),
+	}
+	if err := manager.store.Save(ctx, stored); err != nil {
+		return nil, fmt.Errorf("could not save refresh token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessPayload:    accessPayload,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	buf := make([]byte, refreshTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("could not generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryRefreshTokenStore is an in-process RefreshTokenStore, meant for tests and single instance services
+type MemoryRefreshTokenStore struct {
+	mu              sync.Mutex
+	tokens          map[string]*RefreshToken
+	revokedFamilies map[uuid.UUID]time.Time
+	nextSweep       time.Time
+}
+
+// NewMemoryRefreshTokenStore creates an empty in-memory refresh token store
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens:          make(map[string]*RefreshToken),
+		revokedFamilies: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Save stores a newly issued refresh token, dropping the ones that have expired
+func (store *MemoryRefreshTokenStore) Save(_ context.Context, token RefreshToken) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if now := time.Now(); now.After(store.nextSweep) {
+		store.sweep(now)
+	}
+
+	if _, revoked := store.revokedFamilies[token.FamilyID]; revoked {
+		return ErrRevokedToken
+	}
+	store.tokens[token.Hash] = &token
+	return nil
+}
+
+// Use atomically marks a refresh token as used and returns its previous state
+func (store *MemoryRefreshTokenStore) Use(_ context.Context, hash string) (*RefreshToken, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	token, ok := store.tokens[hash]
+	if !ok {
+		return nil, ErrRefreshTokenNotFound
+	}
+
+	previous := *token
+	_, previous.Revoked = store.revokedFamilies[token.FamilyID]
+	token.Used = true
+	return &previous, nil
+}
+
+// RevokeFamily revokes every refresh token of a token family
+func (store *MemoryRefreshTokenStore) RevokeFamily(_ context.Context, familyID uuid.UUID) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	// the family stays denylisted for as long as any of its tokens could still be presented
+	var expiresAt time.Time
+	for _, token := range store.tokens {
+		if token.FamilyID == familyID && token.ExpiresAt.After(expiresAt) {
+			expiresAt = token.ExpiresAt
+		}
+	}
+	store.revokedFamilies[familyID] = expiresAt
+	return nil
+}
+
+// sweep drops expired tokens and families, the caller must hold the lock
+func (store *MemoryRefreshTokenStore) sweep(now time.Time) {
+	for hash, token := range store.tokens {
+		if now.After(token.ExpiresAt) {
+			delete(store.tokens, hash)
+		}
+	}
+	for familyID, expiresAt := range store.revokedFamilies {
+		if now.After(expiresAt) {
+			delete(store.revokedFamilies, familyID)
+		}
+	}
+	store.nextSweep = now.Add(memoryStoreSweepInterval)
+}
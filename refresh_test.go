@@ -0,0 +1,157 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshManager(t *testing.T) {
+	ctx := context.Background()
+
+	newManager := func(t *testing.T) (*RefreshManager, Maker) {
+		maker, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+
+		manager, err := NewRefreshManager(maker, NewMemoryRefreshTokenStore(), time.Minute, time.Hour)
+		require.NoError(t, err)
+		return manager, maker
+	}
+
+	t.Run("NewRefreshManager", func(t *testing.T) {
+		maker, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+
+		_, err = NewRefreshManager(maker, NewMemoryRefreshTokenStore(), 0, time.Hour)
+		require.Error(t, err)
+
+		_, err = NewRefreshManager(maker, NewMemoryRefreshTokenStore(), time.Hour, time.Minute)
+		require.Error(t, err)
+	})
+
+	t.Run("IssueAndRefresh", func(t *testing.T) {
+		manager, maker := newManager(t)
+
+		pair, err := manager.Issue(ctx, "alice")
+		require.NoError(t, err)
+		require.NotEmpty(t, pair.RefreshToken)
+		require.WithinDuration(t, time.Now().Add(time.Minute), pair.AccessPayload.ExpiredAt, time.Second)
+		require.WithinDuration(t, time.Now().Add(time.Hour), pair.RefreshExpiresAt, time.Second)
+
+		payload, err := maker.VerifyToken(pair.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, "alice", payload.Username)
+
+		// Refresh tokens are opaque, they are never accepted as access tokens
+		_, err = maker.VerifyToken(pair.RefreshToken)
+		require.Error(t, err)
+
+		next, err := manager.Refresh(ctx, pair.RefreshToken)
+		require.NoError(t, err)
+		require.NotEqual(t, pair.RefreshToken, next.RefreshToken)
+		require.NotEqual(t, pair.AccessPayload.ID, next.AccessPayload.ID)
+		require.Equal(t, "alice", next.AccessPayload.Username)
+
+		// Rotation keeps going
+		_, err = manager.Refresh(ctx, next.RefreshToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("ReuseDetection", func(t *testing.T) {
+		manager, maker := newManager(t)
+
+		pair, err := manager.Issue(ctx, "alice")
+		require.NoError(t, err)
+
+		next, err := manager.Refresh(ctx, pair.RefreshToken)
+		require.NoError(t, err)
+
+		// An attacker replays the old refresh token
+		_, err = manager.Refresh(ctx, pair.RefreshToken)
+		require.ErrorIs(t, err, ErrRefreshTokenReused)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		// which kills the legitimate client's refresh token too
+		_, err = manager.Refresh(ctx, next.RefreshToken)
+		require.ErrorIs(t, err, ErrRevokedToken)
+
+		// the access tokens already issued to the family are only cut off by their expiry
+		_, err = maker.VerifyToken(pair.AccessToken)
+		require.NoError(t, err)
+		_, err = maker.VerifyToken(next.AccessToken)
+		require.NoError(t, err)
+
+		// while other families are untouched
+		other, err := manager.Issue(ctx, "alice")
+		require.NoError(t, err)
+		_, err = manager.Refresh(ctx, other.RefreshToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("ConcurrentRefresh", func(t *testing.T) {
+		manager, _ := newManager(t)
+
+		pair, err := manager.Issue(ctx, "alice")
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		results := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := manager.Refresh(ctx, pair.RefreshToken)
+				results <- err
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		succeeded := 0
+		for err := range results {
+			if err == nil {
+				succeeded++
+			}
+		}
+		require.Equal(t, 1, succeeded)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		manager, _ := newManager(t)
+
+		pair, err := manager.Issue(ctx, "alice")
+		require.NoError(t, err)
+
+		require.NoError(t, manager.Revoke(ctx, pair.RefreshToken))
+
+		_, err = manager.Refresh(ctx, pair.RefreshToken)
+		require.ErrorIs(t, err, ErrRevokedToken)
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		manager, _ := newManager(t)
+
+		_, err := manager.Refresh(ctx, "unknown")
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.ErrorIs(t, manager.Revoke(ctx, "unknown"), ErrInvalidToken)
+	})
+
+	t.Run("ExpiredRefreshToken", func(t *testing.T) {
+		maker, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+
+		manager, err := NewRefreshManager(maker, NewMemoryRefreshTokenStore(), time.Millisecond, 20*time.Millisecond)
+		require.NoError(t, err)
+
+		pair, err := manager.Issue(ctx, "alice")
+		require.NoError(t, err)
+
+		time.Sleep(40 * time.Millisecond)
+
+		_, err = manager.Refresh(ctx, pair.RefreshToken)
+		require.ErrorIs(t, err, ErrExpiredToken)
+	})
+}
@@ -0,0 +1,73 @@
+package token
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+// Audience holds the "aud" claim. A single audience is encoded as a plain string
+// and several as a list, both forms are accepted when decoding.
+type Audience []string
+
+// Contains reports whether audience is one of the token's audiences
+func (aud Audience) Contains(audience string) bool {
+	for _, candidate := range aud {
+		if candidate == audience {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON encodes a single audience as a string and several as a list
+func (aud Audience) MarshalJSON() ([]byte, error) {
+	if len(aud) == 1 {
+		return json.Marshal(aud[0])
+	}
+	return json.Marshal([]string(aud))
+}
+
+// UnmarshalJSON accepts either a single audience string or a list of them
+func (aud *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = Audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*aud = list
+	return nil
+}
+
+// numericDate is a time encoded as seconds since the epoch (RFC 7519 section 2)
+type numericDate time.Time
+
+// MarshalJSON encodes the date as whole seconds, the zero time as 0
+func (date numericDate) MarshalJSON() ([]byte, error) {
+	if time.Time(date).IsZero() {
+		return []byte("0"), nil
+	}
+	return json.Marshal(time.Time(date).Unix())
+}
+
+// UnmarshalJSON accepts integer and fractional seconds, 0 decodes to the zero time
+func (date *numericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+
+	if seconds == 0 {
+		*date = numericDate{}
+		return nil
+	}
+
+	whole, fraction := math.Modf(seconds)
+	*date = numericDate(time.Unix(int64(whole), int64(fraction*float64(time.Second))))
+	return nil
+}
@@ -0,0 +1,168 @@
+package token
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisteredClaims(t *testing.T) {
+	issuing := []Option{WithIssuer("auth.example.com"), WithAudience("orders", "billing")}
+
+	for name, maker := range newTestMakers(t) {
+		t.Run(name, func(t *testing.T) {
+			claimsMaker := maker.(ClaimsMaker)
+
+			t.Run("RoundTrip", func(t *testing.T) {
+				payload, err := NewPayload("alice", time.Minute)
+				require.NoError(t, err)
+				payload.Issuer = "auth.example.com"
+				payload.Subject = "user:42"
+				payload.Audience = Audience{"orders", "billing"}
+
+				token, err := claimsMaker.CreateTokenFromPayload(payload)
+				require.NoError(t, err)
+
+				verifiedPayload, err := maker.VerifyToken(token)
+				require.NoError(t, err)
+				require.Equal(t, payload.ID, verifiedPayload.ID)
+				require.Equal(t, "auth.example.com", verifiedPayload.Issuer)
+				require.Equal(t, "user:42", verifiedPayload.Subject)
+				require.Equal(t, Audience{"orders", "billing"}, verifiedPayload.Audience)
+				require.WithinDuration(t, payload.NotBefore, verifiedPayload.NotBefore, time.Second)
+			})
+
+			t.Run("NotYetValid", func(t *testing.T) {
+				payload, err := NewPayload("alice", time.Hour)
+				require.NoError(t, err)
+				payload.NotBefore = time.Now().Add(time.Minute)
+
+				token, err := claimsMaker.CreateTokenFromPayload(payload)
+				require.NoError(t, err)
+
+				verifiedPayload, err := maker.VerifyToken(token)
+				require.ErrorIs(t, err, ErrInvalidToken)
+				require.Nil(t, verifiedPayload)
+			})
+		})
+	}
+
+	t.Run("ExpectedIssuerAndAudience", func(t *testing.T) {
+		secret := randomString(32)
+
+		issuer, err := NewJWTMaker(secret, issuing...)
+		require.NoError(t, err)
+
+		token, payload, err := issuer.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, "auth.example.com", payload.Issuer)
+		require.Equal(t, Audience{"orders", "billing"}, payload.Audience)
+
+		verifier, err := NewJWTMaker(secret, WithExpectedIssuer("auth.example.com"), WithExpectedAudience("billing"))
+		require.NoError(t, err)
+		_, err = verifier.VerifyToken(token)
+		require.NoError(t, err)
+
+		wrongIssuer, err := NewJWTMaker(secret, WithExpectedIssuer("evil.example.com"))
+		require.NoError(t, err)
+		_, err = wrongIssuer.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidIssuer)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		wrongAudience, err := NewJWTMaker(secret, WithExpectedAudience("shipping"))
+		require.NoError(t, err)
+		_, err = wrongAudience.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidAudience)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("PasetoExpectedAudience", func(t *testing.T) {
+		key := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		issuer, err := NewPasetoV3Local(key, WithAudience("orders"))
+		require.NoError(t, err)
+
+		token, _, err := issuer.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		verifier, err := NewPasetoV3Local(key, WithExpectedAudience("orders"))
+		require.NoError(t, err)
+		verifiedPayload, err := verifier.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, Audience{"orders"}, verifiedPayload.Audience)
+
+		wrongAudience, err := NewPasetoV3Local(key, WithExpectedAudience("billing"))
+		require.NoError(t, err)
+		_, err = wrongAudience.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidAudience)
+	})
+
+	t.Run("JWTRegisteredNames", func(t *testing.T) {
+		maker, err := NewJWTMaker(randomString(32), issuing...)
+		require.NoError(t, err)
+
+		token, payload, err := maker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		parts := strings.Split(token, ".")
+		require.Len(t, parts, 3)
+		data, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(t, err)
+
+		var claims map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &claims))
+		require.Equal(t, payload.ID.String(), claims["jti"])
+		require.Equal(t, "auth.example.com", claims["iss"])
+		require.Equal(t, []interface{}{"orders", "billing"}, claims["aud"])
+		require.Equal(t, float64(payload.ExpiredAt.Unix()), claims["exp"])
+		require.Equal(t, float64(payload.IssuedAt.Unix()), claims["iat"])
+		require.Contains(t, claims, "nbf")
+	})
+
+	t.Run("LegacyJWT", func(t *testing.T) {
+		secret := randomString(32)
+		maker, err := NewJWTMaker(secret)
+		require.NoError(t, err)
+
+		// Tokens minted before the registered claim names were adopted must still verify
+		id := uuid.New()
+		legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"id":         id.String(),
+			"username":   "alice",
+			"issued_at":  time.Now().Format(time.RFC3339Nano),
+			"expired_at": time.Now().Add(time.Minute).Format(time.RFC3339Nano),
+		})
+		token, err := legacy.SignedString([]byte(secret))
+		require.NoError(t, err)
+
+		verifiedPayload, err := maker.VerifyToken(token)
+		require.NoError(t, err)
+		require.Equal(t, id, verifiedPayload.ID)
+		require.Equal(t, "alice", verifiedPayload.Username)
+		require.Empty(t, verifiedPayload.Claims)
+	})
+}
+
+func TestAudience(t *testing.T) {
+	single, err := json.Marshal(Audience{"orders"})
+	require.NoError(t, err)
+	require.JSONEq(t, `"orders"`, string(single))
+
+	multiple, err := json.Marshal(Audience{"orders", "billing"})
+	require.NoError(t, err)
+	require.JSONEq(t, `["orders","billing"]`, string(multiple))
+
+	var aud Audience
+	require.NoError(t, json.Unmarshal([]byte(`"orders"`), &aud))
+	require.Equal(t, Audience{"orders"}, aud)
+	require.NoError(t, json.Unmarshal([]byte(`["orders","billing"]`), &aud))
+	require.True(t, aud.Contains("billing"))
+	require.False(t, aud.Contains("shipping"))
+	require.Error(t, json.Unmarshal([]byte(`42`), &aud))
+}
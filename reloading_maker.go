@@ -0,0 +1,229 @@
+package token
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultReloadInterval is how often a ReloadingMaker checks its key source for changes
+const defaultReloadInterval = 10 * time.Second
+
+// ReloadEvent reports a reload of a ReloadingMaker's keys
+type ReloadEvent struct {
+
+	// Keys holds the names of the keys read from the source
+	Keys []string
+
+	// Err is why the keys couldn't be loaded, the maker built from the previous keys stays in use
+	Err error
+}
+
+// ReloadOption configures a ReloadingMaker
+type ReloadOption func(*ReloadingMaker)
+
+// WithReloadInterval sets how often the key source is checked for changes, 10 seconds by default.
+// A negative interval turns the checks off, keys are then only reloaded by Reload
+func WithReloadInterval(interval time.Duration) ReloadOption {
+	return func(maker *ReloadingMaker) {
+		maker.interval = interval
+	}
+}
+
+// WithReloadHook calls hook after every reload of changed keys, successful or not
+func WithReloadHook(hook func(ReloadEvent)) ReloadOption {
+	return func(maker *ReloadingMaker) {
+		maker.hook = hook
+	}
+}
+
+// ReloadingMaker is a Maker built from the keys of a KeySource, and rebuilt when they change, so keys can
+// be rotated by updating a file or a mounted secret without a restart. The source is polled, which also
+// catches the symlink swaps Kubernetes updates secrets with. Keys that can't be read or don't build a
+// maker never replace working ones, the previous maker stays in use until the source is fixed
+type ReloadingMaker struct {
+	source   KeySource
+	build    func(keys Keys) (Maker, error)
+	interval time.Duration
+	hook     func(ReloadEvent)
+
+	mu    sync.RWMutex
+	maker ContextMaker
+
+	// reloadMu serializes reloads, fingerprint identifies the keys last read
+	reloadMu    sync.Mutex
+	fingerprint [sha256.Size]byte
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewReloadingMaker reads the keys of source and builds the maker with build, failing if either fails.
+// The source is then watched for changes until Close is called
+func NewReloadingMaker(ctx context.Context, source KeySource, build func(keys Keys) (Maker, error), opts ...ReloadOption) (*ReloadingMaker, error) {
+	if source == nil || build == nil {
+		return nil, fmt.Errorf("key source and maker builder are required")
+	}
+
+	maker := &ReloadingMaker{
+		source:   source,
+		build:    build,
+		interval: defaultReloadInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(maker)
+	}
+
+	keys, err := source.Keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not load keys: %w", err)
+	}
+	built, err := build(keys)
+	if err != nil {
+		return nil, fmt.Errorf("could not build maker: %w", err)
+	}
+	maker.maker = AsContextMaker(built)
+	maker.fingerprint = fingerprintKeys(keys)
+
+	if maker.interval > 0 {
+		go maker.watch()
+	} else {
+		close(maker.done)
+	}
+	return maker, nil
+}
+
+// Reload reads the keys of the source and, if they changed, rebuilds the maker. When the keys can't be read
+// or the maker can't be built, the error is returned and the current maker stays in use
+func (maker *ReloadingMaker) Reload(ctx context.Context) error {
+	maker.reloadMu.Lock()
+	defer maker.reloadMu.Unlock()
+
+	keys, err := maker.source.Keys(ctx)
+	if err != nil {
+		err = fmt.Errorf("could not load keys: %w", err)
+		maker.notify(ReloadEvent{Err: err})
+		return err
+	}
+
+	// unchanged keys aren't built again, and broken ones aren't reported on every check
+	fingerprint := fingerprintKeys(keys)
+	if fingerprint == maker.fingerprint {
+		return nil
+	}
+	maker.fingerprint = fingerprint
+
+	built, err := maker.build(keys)
+	if err != nil {
+		err = fmt.Errorf("could not build maker: %w", err)
+		maker.notify(ReloadEvent{Keys: keys.Names(), Err: err})
+		return err
+	}
+
+	maker.mu.Lock()
+	maker.maker = AsContextMaker(built)
+	maker.mu.Unlock()
+
+	maker.notify(ReloadEvent{Keys: keys.Names()})
+	return nil
+}
+
+// Close stops watching the key source, the maker keeps working with the keys it has
+func (maker *ReloadingMaker) Close() error {
+	maker.closeOnce.Do(func() {
+		close(maker.stop)
+	})
+	<-maker.done
+	return nil
+}
+
+// Current returns the maker built from the latest valid keys
+func (maker *ReloadingMaker) Current() Maker {
+	return maker.current()
+}
+
+// CreateToken creates a token with the current maker
+func (maker *ReloadingMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return maker.current().CreateToken(username, duration)
+}
+
+// VerifyToken verifies a token with the current maker
+func (maker *ReloadingMaker) VerifyToken(token string) (*Payload, error) {
+	return maker.current().VerifyToken(token)
+}
+
+// CreateTokenContext creates a token with the current maker
+func (maker *ReloadingMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return maker.current().CreateTokenContext(ctx, username, duration)
+}
+
+// VerifyTokenContext verifies a token with the current maker
+func (maker *ReloadingMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	return maker.current().VerifyTokenContext(ctx, token)
+}
+
+func (maker *ReloadingMaker) current() ContextMaker {
+	maker.mu.RLock()
+	defer maker.mu.RUnlock()
+	return maker.maker
+}
+
+// makerOptions returns the options of the current maker, see optionsOf
+func (maker *ReloadingMaker) makerOptions() options {
+	return optionsOf(maker.current())
+}
+
+// watch reloads the keys every interval until Close is called
+func (maker *ReloadingMaker) watch() {
+	defer close(maker.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-maker.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(maker.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-maker.stop:
+			return
+		case <-ticker.C:
+			// failures are reported to the hook, the source is read again on the next tick
+			_ = maker.Reload(ctx)
+		}
+	}
+}
+
+func (maker *ReloadingMaker) notify(event ReloadEvent) {
+	if maker.hook != nil {
+		maker.hook(event)
+	}
+}
+
+// fingerprintKeys hashes the names and contents of keys, so a change to any of them is noticed
+func fingerprintKeys(keys Keys) [sha256.Size]byte {
+	hash := sha256.New()
+	for _, name := range keys.Names() {
+		for _, piece := range [][]byte{[]byte(name), keys[name]} {
+			_ = binary.Write(hash, binary.LittleEndian, uint64(len(piece)))
+			hash.Write(piece)
+		}
+	}
+
+	var fingerprint [sha256.Size]byte
+	hash.Sum(fingerprint[:0])
+	return fingerprint
+}
@@ -0,0 +1,185 @@
+package token
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+// writeV4KeyPair writes a PASETO v4 key pair to dir the way a secret is updated, file by file
+func writeV4KeyPair(t *testing.T, dir string, secret paseto.V4AsymmetricSecretKey) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "private"), []byte(secret.ExportHex()+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "public"), []byte(secret.Public().ExportHex()+"\n"), 0o600))
+}
+
+func buildV4Public(keys Keys) (Maker, error) {
+	privateKey, err := keys.Get("private")
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := keys.Get("public")
+	if err != nil {
+		return nil, err
+	}
+	return NewPasetoV4Public(privateKey, publicKey)
+}
+
+// reloadEvents records the events of a ReloadingMaker
+type reloadEvents struct {
+	mu     sync.Mutex
+	events []ReloadEvent
+}
+
+func (r *reloadEvents) record(event ReloadEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *reloadEvents) list() []ReloadEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ReloadEvent(nil), r.events...)
+}
+
+func TestReloadingMaker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Reload", func(t *testing.T) {
+		dir := t.TempDir()
+		writeV4KeyPair(t, dir, paseto.NewV4AsymmetricSecretKey())
+
+		var events reloadEvents
+		maker, err := NewReloadingMaker(ctx, DirectoryKeySource(dir), buildV4Public,
+			WithReloadInterval(-1), WithReloadHook(events.record))
+		require.NoError(t, err)
+		defer maker.Close()
+
+		oldToken, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+
+		// unchanged keys aren't rebuilt
+		require.NoError(t, maker.Reload(ctx))
+		require.Empty(t, events.list())
+
+		writeV4KeyPair(t, dir, paseto.NewV4AsymmetricSecretKey())
+		require.NoError(t, maker.Reload(ctx))
+		require.Equal(t, []ReloadEvent{{Keys: []string{"private", "public"}}}, events.list())
+
+		newToken, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+		_, err = maker.VerifyToken(newToken)
+		require.NoError(t, err)
+		_, err = maker.VerifyToken(oldToken)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("InvalidKeysAreIgnored", func(t *testing.T) {
+		dir := t.TempDir()
+		writeV4KeyPair(t, dir, paseto.NewV4AsymmetricSecretKey())
+
+		var events reloadEvents
+		maker, err := NewReloadingMaker(ctx, DirectoryKeySource(dir), buildV4Public,
+			WithReloadInterval(-1), WithReloadHook(events.record))
+		require.NoError(t, err)
+		defer maker.Close()
+
+		token, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+
+		// a half written update: the private key of one pair with the public key of another
+		other := paseto.NewV4AsymmetricSecretKey()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "private"), []byte(other.ExportHex()), 0o600))
+		err = maker.Reload(ctx)
+		require.ErrorIs(t, err, ErrKeyMismatch)
+
+		// garbage
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "public"), []byte("not a key"), 0o600))
+		require.Error(t, maker.Reload(ctx))
+
+		// a source that can't be read
+		require.NoError(t, os.RemoveAll(dir))
+		require.Error(t, maker.Reload(ctx))
+
+		recorded := events.list()
+		require.Len(t, recorded, 3)
+		for _, event := range recorded {
+			require.Error(t, event.Err)
+		}
+
+		// the old keys are still in use
+		_, err = maker.VerifyToken(token)
+		require.NoError(t, err)
+		_, _, err = maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		dir := t.TempDir()
+		writeV4KeyPair(t, dir, paseto.NewV4AsymmetricSecretKey())
+
+		var events reloadEvents
+		maker, err := NewReloadingMaker(ctx, DirectoryKeySource(dir), buildV4Public,
+			WithReloadInterval(10*time.Millisecond), WithReloadHook(events.record))
+		require.NoError(t, err)
+
+		oldToken, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+
+		writeV4KeyPair(t, dir, paseto.NewV4AsymmetricSecretKey())
+		require.Eventually(t, func() bool {
+			_, err := maker.VerifyToken(oldToken)
+			return err != nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, maker.Close())
+		require.NoError(t, maker.Close())
+
+		// nothing is reloaded after Close
+		reloads := len(events.list())
+		writeV4KeyPair(t, dir, paseto.NewV4AsymmetricSecretKey())
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, events.list(), reloads)
+	})
+
+	t.Run("Context", func(t *testing.T) {
+		store := &contextRecordingStore{RevocationStore: NewMemoryRevocationStore()}
+		maker, err := NewReloadingMaker(ctx, StaticKeySource(map[string]string{"key": paseto.NewV4SymmetricKey().ExportHex()}), func(keys Keys) (Maker, error) {
+			key, err := keys.Get("key")
+			if err != nil {
+				return nil, err
+			}
+			inner, err := NewPasetoV4Local(key)
+			if err != nil {
+				return nil, err
+			}
+			return NewRevocableMaker(inner, store), nil
+		}, WithReloadInterval(-1))
+		require.NoError(t, err)
+
+		token, _, err := maker.CreateToken("test_user", time.Minute)
+		require.NoError(t, err)
+
+		_, err = maker.VerifyTokenContext(context.WithValue(ctx, contextKey{}, "request-1"), token)
+		require.NoError(t, err)
+		require.Equal(t, "request-1", store.ctx.Value(contextKey{}))
+	})
+
+	t.Run("InvalidInitialKeys", func(t *testing.T) {
+		_, err := NewReloadingMaker(ctx, DirectoryKeySource(t.TempDir()), buildV4Public)
+		require.Error(t, err)
+
+		_, err = NewReloadingMaker(ctx, DirectoryKeySource(filepath.Join(t.TempDir(), "missing")), buildV4Public)
+		require.Error(t, err)
+
+		_, err = NewReloadingMaker(ctx, nil, buildV4Public)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,91 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/fsobh/token"
+)
+
+// Client signs through a Server listening on a Unix socket. It is a token.KeySigner, pass it to
+// e.g. token.NewAsymJWTMakerWithSigner
+type Client struct {
+	socketPath string
+	publicKey  crypto.PublicKey
+}
+
+var _ token.KeySigner = (*Client)(nil)
+
+// Dial connects to the Server at socketPath and fetches its public key
+func Dial(ctx context.Context, socketPath string) (*Client, error) {
+	client := &Client{socketPath: socketPath}
+
+	resp, err := client.call(ctx, request{Op: "public"})
+	if err != nil {
+		return nil, err
+	}
+	client.publicKey, err = x509.ParsePKIXPublicKey(resp.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the remote signer's public key: %w", err)
+	}
+
+	return client, nil
+}
+
+// Public returns the public key of the Server's private key
+func (client *Client) Public() crypto.PublicKey {
+	return client.publicKey
+}
+
+// Sign has the Server sign digest
+func (client *Client) Sign(ctx context.Context, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	req, err := newSignRequest(digest, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.call(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// call sends req on a new connection and reads the response, giving up when ctx is done
+func (client *Client) call(ctx context.Context, req request) (response, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", client.socketPath)
+	if err != nil {
+		return response{}, fmt.Errorf("could not reach the remote signer: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	var resp response
+	err = json.NewEncoder(conn).Encode(req)
+	if err == nil {
+		err = json.NewDecoder(conn).Decode(&resp)
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return response{}, ctxErr
+		}
+		return response{}, fmt.Errorf("could not talk to the remote signer: %w", err)
+	}
+
+	if resp.Error != "" {
+		return response{}, fmt.Errorf("%w: %s", ErrRemote, resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,56 @@
+// Package remotesigner is a stand-in for a KMS or signing agent. A Server holds a private key read from a file
+// and signs over a Unix socket, a Client is the token.KeySigner that asks it to, so tokens can be signed
+// without the key in the process that creates them
+package remotesigner
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+)
+
+// request is what a Client sends, one per connection
+type request struct {
+
+	// Op is "public" to fetch the public key or "sign" to sign Digest
+	Op     string `json:"op"`
+	Digest []byte `json:"digest,omitempty"`
+
+	// Hash is the crypto.Hash Digest was computed with, 0 for Ed25519
+	Hash crypto.Hash `json:"hash,omitempty"`
+
+	// PSSSaltLength is set for RSA-PSS signatures
+	PSSSaltLength *int `json:"pss_salt_length,omitempty"`
+}
+
+// response is what the Server answers
+type response struct {
+	PublicKey []byte `json:"public_key,omitempty"` // PKIX, ASN.1 DER
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ErrRemote wraps the errors the Server reports back to a Client
+var ErrRemote = errors.New("remote signer")
+
+// signerOpts returns the crypto.SignerOpts a request stands for
+func (r request) signerOpts() crypto.SignerOpts {
+	if r.PSSSaltLength != nil {
+		return &rsa.PSSOptions{SaltLength: *r.PSSSaltLength, Hash: r.Hash}
+	}
+	return r.Hash
+}
+
+// newSignRequest encodes digest and opts as a sign request
+func newSignRequest(digest []byte, opts crypto.SignerOpts) (request, error) {
+	req := request{Op: "sign", Digest: digest, Hash: opts.HashFunc()}
+	if pss, ok := opts.(*rsa.PSSOptions); ok {
+		saltLength := pss.SaltLength
+		req.PSSSaltLength = &saltLength
+	}
+	if req.Hash != 0 && len(digest) != req.Hash.Size() {
+		return request{}, fmt.Errorf("digest is %d bytes long, %s needs %d", len(digest), req.Hash, req.Hash.Size())
+	}
+	return req, nil
+}
@@ -0,0 +1,289 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/fsobh/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+// startServer writes key to a PEM file, serves it on a Unix socket and dials it
+func startServer(t *testing.T, key crypto.Signer) *Client {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "signing.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	server, err := NewServer(keyFile)
+	require.NoError(t, err)
+
+	socketPath := filepath.Join(dir, "signer.sock")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, server.ListenAndServe(socketPath))
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, server.Close())
+		<-done
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socketPath)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	client, err := Dial(ctx, socketPath)
+	require.NoError(t, err)
+	return client
+}
+
+func TestRemoteSigner(t *testing.T) {
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	edSigner := startServer(t, edPrivateKey)
+	p256Signer := startServer(t, p256Key)
+	p384Signer := startServer(t, p384Key)
+	rsaSigner := startServer(t, rsaKey)
+
+	require.Equal(t, edPublicKey, edSigner.Public())
+	require.True(t, p384Key.PublicKey.Equal(p384Signer.Public()))
+
+	v3PublicKey, err := paseto.NewV3AsymmetricPublicKeyFromEcdsa(p384Key.PublicKey)
+	require.NoError(t, err)
+
+	type pair struct {
+		maker    func() (token.Maker, error)
+		verifier func() (token.Maker, error)
+	}
+	pairs := map[string]pair{
+		"AsymJWT": {
+			func() (token.Maker, error) { return token.NewAsymJWTMakerWithSigner(edSigner) },
+			func() (token.Maker, error) { return token.NewAsymJWTVerifier(edPublicKey) },
+		},
+		"RS256": {
+			func() (token.Maker, error) { return token.NewRSAJWTMakerWithSigner("RS256", rsaSigner) },
+			func() (token.Maker, error) { return token.NewRSAJWTVerifier("RS256", &rsaKey.PublicKey) },
+		},
+		"PS256": {
+			func() (token.Maker, error) { return token.NewRSAJWTMakerWithSigner("PS256", rsaSigner) },
+			func() (token.Maker, error) { return token.NewRSAJWTVerifier("PS256", &rsaKey.PublicKey) },
+		},
+		"ES256": {
+			func() (token.Maker, error) { return token.NewECDSAJWTMakerWithSigner(p256Signer) },
+			func() (token.Maker, error) { return token.NewECDSAJWTVerifier(&p256Key.PublicKey) },
+		},
+		"ES384": {
+			func() (token.Maker, error) { return token.NewECDSAJWTMakerWithSigner(p384Signer) },
+			func() (token.Maker, error) { return token.NewECDSAJWTVerifier(&p384Key.PublicKey) },
+		},
+		"PasetoV2Public": {
+			func() (token.Maker, error) { return token.NewPasetoV2PublicWithSigner(edSigner) },
+			func() (token.Maker, error) { return token.NewPasetoV2PublicVerifier(hex.EncodeToString(edPublicKey)) },
+		},
+		"PasetoV3Public": {
+			func() (token.Maker, error) { return token.NewPasetoV3PublicWithSigner(p384Signer) },
+			func() (token.Maker, error) { return token.NewPasetoV3PublicVerifier(v3PublicKey.ExportHex()) },
+		},
+		"PasetoV4Public": {
+			func() (token.Maker, error) { return token.NewPasetoV4PublicWithSigner(edSigner) },
+			func() (token.Maker, error) { return token.NewPasetoV4PublicVerifier(hex.EncodeToString(edPublicKey)) },
+		},
+	}
+
+	for name, pair := range pairs {
+		t.Run(name, func(t *testing.T) {
+			maker, err := pair.maker()
+			require.NoError(t, err)
+			verifier, err := pair.verifier()
+			require.NoError(t, err)
+
+			signed, payload, err := maker.CreateToken("test_user", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := verifier.VerifyToken(signed)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+			require.Equal(t, "test_user", verifiedPayload.Username)
+		})
+	}
+
+	t.Run("Unreachable", func(t *testing.T) {
+		maker, err := token.NewAsymJWTMakerWithSigner(&Client{socketPath: filepath.Join(t.TempDir(), "gone.sock"), publicKey: edPublicKey})
+		require.NoError(t, err)
+
+		_, _, err = maker.CreateToken("test_user", time.Minute)
+		require.Error(t, err)
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		maker, err := token.NewPasetoV4PublicWithSigner(edSigner)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err = maker.CreateTokenContext(ctx, "test_user", time.Minute)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("RemoteError", func(t *testing.T) {
+		_, err := p256Signer.Sign(context.Background(), []byte("short"), crypto.SHA256)
+		require.Error(t, err)
+
+		// Ed25519 keys sign messages, not SHA-256 digests, the server's error comes back to the client
+		digest := make([]byte, crypto.SHA256.Size())
+		_, err = edSigner.Sign(context.Background(), digest, crypto.SHA256)
+		require.ErrorIs(t, err, ErrRemote)
+	})
+}
+
+func TestServerCloseWhileServing(t *testing.T) {
+	_, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	server := NewServerFromSigner(edPrivateKey)
+
+	socketPath := filepath.Join(t.TempDir(), "signer.sock")
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+
+	// keep connecting while the server closes, Close must wait for every connection it accepted
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				conn, err := net.Dial("unix", socketPath)
+				if err != nil {
+					continue
+				}
+				conn.Close()
+			}
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, server.Close())
+	require.NoError(t, <-done)
+	close(stop)
+	wg.Wait()
+}
+
+func TestListenAndServeSocket(t *testing.T) {
+	_, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	listen := func(t *testing.T, socketPath string) (*Server, chan error) {
+		server := NewServerFromSigner(edPrivateKey)
+		done := make(chan error, 1)
+		go func() {
+			done <- server.ListenAndServe(socketPath)
+		}()
+		return server, done
+	}
+
+	t.Run("OwnerOnly", func(t *testing.T) {
+		dir := t.TempDir()
+		socketPath := filepath.Join(dir, "signer.sock")
+		server, done := listen(t, socketPath)
+
+		// the socket only ever appears at its path with its final mode
+		var info os.FileInfo
+		require.Eventually(t, func() bool {
+			info, err = os.Lstat(socketPath)
+			return err == nil
+		}, 5*time.Second, time.Millisecond)
+		require.NotZero(t, info.Mode()&os.ModeSocket)
+		require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		require.NoError(t, server.Close())
+		require.NoError(t, <-done)
+		_, err = os.Lstat(socketPath)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("StaleSocket", func(t *testing.T) {
+		socketPath := filepath.Join(t.TempDir(), "signer.sock")
+		stale, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		require.NoError(t, stale.Close())
+
+		server, done := listen(t, socketPath)
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("unix", socketPath)
+			if err != nil {
+				return false
+			}
+			conn.Close()
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// a socket in use isn't replaced
+		err = NewServerFromSigner(edPrivateKey).ListenAndServe(socketPath)
+		require.ErrorContains(t, err, "in use")
+
+		require.NoError(t, server.Close())
+		require.NoError(t, <-done)
+	})
+
+	t.Run("Symlink", func(t *testing.T) {
+		dir := t.TempDir()
+		target := filepath.Join(dir, "target")
+		require.NoError(t, os.WriteFile(target, nil, 0o600))
+		socketPath := filepath.Join(dir, "signer.sock")
+		require.NoError(t, os.Symlink(target, socketPath))
+
+		err := NewServerFromSigner(edPrivateKey).ListenAndServe(socketPath)
+		require.ErrorContains(t, err, "symlink")
+		_, err = os.Stat(target)
+		require.NoError(t, err)
+
+		err = NewServerFromSigner(edPrivateKey).ListenAndServe(target)
+		require.ErrorContains(t, err, "not a socket")
+	})
+}
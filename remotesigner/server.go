@@ -0,0 +1,221 @@
+package remotesigner
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsobh/token"
+)
+
+// connectionTimeout bounds how long the Server waits on one client
+const connectionTimeout = 10 * time.Second
+
+// Server signs with a private key on behalf of Clients
+type Server struct {
+	signer crypto.Signer
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a Server for the PEM encoded private key in keyFile, a PKCS #8, EC or PKCS #1 RSA key
+func NewServer(keyFile string) (*Server, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read signing key: %w", err)
+	}
+
+	signer, err := token.ParsePEMPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse signing key: %w", err)
+	}
+	return NewServerFromSigner(signer), nil
+}
+
+// NewServerFromSigner creates a Server signing with signer
+func NewServerFromSigner(signer crypto.Signer) *Server {
+	return &Server{signer: signer}
+}
+
+// ListenAndServe listens on the Unix socket at socketPath, readable by the current user only, and serves on it
+// until Close is called. A stale socket left at socketPath is replaced, anything else there is an error
+func (server *Server) ListenAndServe(socketPath string) error {
+	if err := removeStaleSocket(socketPath); err != nil {
+		return err
+	}
+
+	listener, err := listenPrivate(socketPath)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// listenPrivate listens on a socket created in a directory only the current user can enter, and only moves it to
+// socketPath once it is restricted to the current user, so nobody else can ever connect to it
+func listenPrivate(socketPath string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".signer-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	privatePath := filepath.Join(dir, "signer.sock")
+	listener, err := net.Listen("unix", privatePath)
+	if err != nil {
+		return nil, err
+	}
+	// the socket is removed by socketListener.Close once it has moved
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(privatePath, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(privatePath, socketPath); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &socketListener{Listener: listener, path: socketPath}, nil
+}
+
+// removeStaleSocket removes the socket at socketPath if no server listens on it any more. Symlinks and other
+// files are never removed
+func removeStaleSocket(socketPath string) error {
+	info, err := os.Lstat(socketPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return fmt.Errorf("%s is a symlink, not a socket", socketPath)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", socketPath)
+	}
+
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another server", socketPath)
+	}
+	return os.Remove(socketPath)
+}
+
+// socketListener is a Unix socket listener that removes its socket when it is closed
+type socketListener struct {
+	net.Listener
+	path string
+}
+
+func (listener *socketListener) Close() error {
+	err := listener.Listener.Close()
+	if removeErr := os.Remove(listener.path); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+		err = errors.Join(err, removeErr)
+	}
+	return err
+}
+
+// Serve accepts connections on listener until Close is called, it then returns nil
+func (server *Server) Serve(listener net.Listener) error {
+	server.mu.Lock()
+	if server.closed {
+		server.mu.Unlock()
+		listener.Close()
+		return nil
+	}
+	server.listeners = append(server.listeners, listener)
+	server.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			server.mu.Lock()
+			closed := server.closed
+			server.mu.Unlock()
+			if closed || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		// Add mustn't race with the Wait in Close, so it happens under the lock, unless the server is closed
+		server.mu.Lock()
+		if server.closed {
+			server.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		server.wg.Add(1)
+		server.mu.Unlock()
+
+		go func() {
+			defer server.wg.Done()
+			server.handle(conn)
+		}()
+	}
+}
+
+// Close stops the Server's listeners and waits for the requests in flight
+func (server *Server) Close() error {
+	server.mu.Lock()
+	server.closed = true
+	listeners := server.listeners
+	server.listeners = nil
+	server.mu.Unlock()
+
+	var err error
+	for _, listener := range listeners {
+		err = errors.Join(err, listener.Close())
+	}
+	server.wg.Wait()
+	return err
+}
+
+func (server *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(connectionTimeout))
+
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+
+	resp, err := server.serve(req)
+	if err != nil {
+		resp = response{Error: err.Error()}
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+func (server *Server) serve(req request) (response, error) {
+	switch req.Op {
+	case "public":
+		publicKey, err := x509.MarshalPKIXPublicKey(server.signer.Public())
+		if err != nil {
+			return response{}, err
+		}
+		return response{PublicKey: publicKey}, nil
+	case "sign":
+		signature, err := server.signer.Sign(rand.Reader, req.Digest, req.signerOpts())
+		if err != nil {
+			return response{}, err
+		}
+		return response{Signature: signature}, nil
+	default:
+		return response{}, fmt.Errorf("unknown operation %q", req.Op)
+	}
+}
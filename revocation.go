@@ -0,0 +1,185 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrRevokedToken is returned when a token has been denylisted before it expired
+var ErrRevokedToken = fmt.Errorf("%w: token has been revoked", ErrInvalidToken)
+
+// memoryStoreSweepInterval is how often the in-memory stores drop the entries of expired tokens
+const memoryStoreSweepInterval = time.Minute
+
+// RevocationStore keeps track of the IDs of revoked tokens
+type RevocationStore interface {
+
+	// Revoke denylists a token ID until expiresAt, after which the token is expired anyway
+	Revoke(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+
+	// IsRevoked reports whether a token ID has been revoked
+	IsRevoked(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+// MemoryRevocationStore is an in-process RevocationStore. Entries are dropped once the token they
+// belong to has expired, so the store only ever holds the revoked tokens that are still live
+type MemoryRevocationStore struct {
+	mu        sync.Mutex
+	revoked   map[uuid.UUID]time.Time
+	nextSweep time.Time
+	options   options
+}
+
+// NewMemoryRevocationStore creates an empty in-memory revocation store. Of the options, only WithClock applies:
+// give it the clock of the makers whose tokens it holds
+func NewMemoryRevocationStore(opts ...Option) *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[uuid.UUID]time.Time), options: newOptions(opts)}
+}
+
+// Revoke denylists a token ID until expiresAt
+func (store *MemoryRevocationStore) Revoke(_ context.Context, id uuid.UUID, expiresAt time.Time) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := store.options.now()
+	if now.After(store.nextSweep) {
+		store.sweep(now)
+	}
+
+	if expiresAt.After(now) {
+		store.revoked[id] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked reports whether a token ID has been revoked and its token hasn't expired yet
+func (store *MemoryRevocationStore) IsRevoked(_ context.Context, id uuid.UUID) (bool, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	expiresAt, ok := store.revoked[id]
+	if !ok {
+		return false, nil
+	}
+
+	if store.options.now().After(expiresAt) {
+		delete(store.revoked, id)
+		return false, nil
+	}
+	return true, nil
+}
+
+// Len returns the number of revoked tokens the store currently holds
+func (store *MemoryRevocationStore) Len() int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.sweep(store.options.now())
+	return len(store.revoked)
+}
+
+// sweep drops the entries of expired tokens, the caller must hold the lock
+func (store *MemoryRevocationStore) sweep(now time.Time) {
+	for id, expiresAt := range store.revoked {
+		if now.After(expiresAt) {
+			delete(store.revoked, id)
+		}
+	}
+	store.nextSweep = now.Add(memoryStoreSweepInterval)
+}
+
+// RevocableMaker wraps a Maker so tokens can be revoked before they expire
+type RevocableMaker struct {
+	maker Maker
+	store RevocationStore
+}
+
+// NewRevocableMaker wraps maker, rejecting the tokens whose IDs are revoked in store. Revocations are kept
+// until the leeway maker verifies with has passed too, see WithLeeway
+func NewRevocableMaker(maker Maker, store RevocationStore) *RevocableMaker {
+	return &RevocableMaker{maker: maker, store: store}
+}
+
+func (revocable *RevocableMaker) makerOptions() options {
+	return optionsOf(revocable.maker)
+}
+
+// CreateToken Create a token for a specific username with a duration
+func (revocable *RevocableMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	return revocable.maker.CreateToken(username, duration)
+}
+
+// CreateTokenContext Create a token for a specific username with a duration
+func (revocable *RevocableMaker) CreateTokenContext(ctx context.Context, username string, duration time.Duration) (string, *Payload, error) {
+	return AsContextMaker(revocable.maker).CreateTokenContext(ctx, username, duration)
+}
+
+// CreateTokenWithClaims Create a token for a specific username with a duration and custom claims
+func (revocable *RevocableMaker) CreateTokenWithClaims(username string, duration time.Duration, claims interface{}) (string, *Payload, error) {
+	maker, err := asClaimsMaker(revocable.maker)
+	if err != nil {
+		return "", nil, err
+	}
+	return maker.CreateTokenWithClaims(username, duration, claims)
+}
+
+// CreateTokenFromPayload Create a token for a payload built by the caller
+func (revocable *RevocableMaker) CreateTokenFromPayload(payload *Payload) (string, error) {
+	maker, err := asClaimsMaker(revocable.maker)
+	if err != nil {
+		return "", err
+	}
+	return maker.CreateTokenFromPayload(payload)
+}
+
+// VerifyToken Check if the input token is valid and hasn't been revoked
+func (revocable *RevocableMaker) VerifyToken(token string) (*Payload, error) {
+	return revocable.VerifyTokenContext(context.Background(), token)
+}
+
+// VerifyTokenContext Check if the input token is valid and hasn't been revoked, passing ctx on to the store
+func (revocable *RevocableMaker) VerifyTokenContext(ctx context.Context, token string) (*Payload, error) {
+	payload, err := AsContextMaker(revocable.maker).VerifyTokenContext(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := revocable.store.IsRevoked(ctx, payload.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, newVerificationError(ReasonRevoked, nil)
+	}
+
+	return payload, nil
+}
+
+// Revoke revokes the token the payload belongs to, until it expires and its leeway has passed
+func (revocable *RevocableMaker) Revoke(payload *Payload) error {
+	return revocable.RevokeContext(context.Background(), payload)
+}
+
+// RevokeContext revokes the token the payload belongs to, until it expires and its leeway has passed, passing
+// ctx on to the store
+func (revocable *RevocableMaker) RevokeContext(ctx context.Context, payload *Payload) error {
+	return revocable.store.Revoke(ctx, payload.ID, payload.ExpiredAt.Add(optionsOf(revocable.maker).leeway))
+}
+
+// RevokeToken verifies a token and revokes it, e.g. when its user logs out
+func (revocable *RevocableMaker) RevokeToken(token string) error {
+	return revocable.RevokeTokenContext(context.Background(), token)
+}
+
+// RevokeTokenContext verifies a token and revokes it, passing ctx on to the store
+func (revocable *RevocableMaker) RevokeTokenContext(ctx context.Context, token string) error {
+	payload, err := revocable.VerifyTokenContext(ctx, token)
+	if err != nil {
+		return err
+	}
+	return revocable.RevokeContext(ctx, payload)
+}
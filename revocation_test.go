@@ -0,0 +1,133 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RevokeAndExpire", func(t *testing.T) {
+		store := NewMemoryRevocationStore()
+		live, soon := uuid.New(), uuid.New()
+
+		require.NoError(t, store.Revoke(ctx, live, time.Now().Add(time.Hour)))
+		require.NoError(t, store.Revoke(ctx, soon, time.Now().Add(50*time.Millisecond)))
+		require.Equal(t, 2, store.Len())
+
+		revoked, err := store.IsRevoked(ctx, live)
+		require.NoError(t, err)
+		require.True(t, revoked)
+
+		revoked, err = store.IsRevoked(ctx, uuid.New())
+		require.NoError(t, err)
+		require.False(t, revoked)
+
+		// Entries go away once their token has expired
+		time.Sleep(100 * time.Millisecond)
+
+		revoked, err = store.IsRevoked(ctx, soon)
+		require.NoError(t, err)
+		require.False(t, revoked)
+		require.Equal(t, 1, store.Len())
+	})
+
+	t.Run("AlreadyExpired", func(t *testing.T) {
+		store := NewMemoryRevocationStore()
+
+		require.NoError(t, store.Revoke(ctx, uuid.New(), time.Now().Add(-time.Minute)))
+		require.Zero(t, store.Len())
+	})
+}
+
+func TestRevocableMaker(t *testing.T) {
+	for name, maker := range newTestMakers(t) {
+		t.Run(name, func(t *testing.T) {
+			store := NewMemoryRevocationStore()
+			revocable := NewRevocableMaker(maker, store)
+
+			token, payload, err := revocable.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+
+			verifiedPayload, err := revocable.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+
+			require.NoError(t, revocable.RevokeToken(token))
+
+			verifiedPayload, err = revocable.VerifyToken(token)
+			require.ErrorIs(t, err, ErrRevokedToken)
+			require.ErrorIs(t, err, ErrInvalidToken)
+			require.Nil(t, verifiedPayload)
+
+			// Other tokens are unaffected
+			otherToken, _, err := revocable.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+			_, err = revocable.VerifyToken(otherToken)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("RevokePayload", func(t *testing.T) {
+		maker, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+		revocable := NewRevocableMaker(maker, NewMemoryRevocationStore())
+
+		token, payload, err := revocable.CreateTokenWithClaims("alice", time.Minute, map[string]string{"email": "alice@example.com"})
+		require.NoError(t, err)
+
+		require.NoError(t, revocable.Revoke(payload))
+		_, err = revocable.VerifyToken(token)
+		require.ErrorIs(t, err, ErrRevokedToken)
+	})
+
+	t.Run("Leeway", func(t *testing.T) {
+		now := time.Now()
+		clock := ClockFunc(func() time.Time { return now })
+		maker, err := NewJWTMaker(randomString(32), WithLeeway(time.Minute), WithClock(clock), WithKeyID("key-1"))
+		require.NoError(t, err)
+		keyring, err := NewKeyring(maker)
+		require.NoError(t, err)
+
+		// the leeway is read from the wrapped maker, through wrappers like a Keyring too
+		for name, wrapped := range map[string]Maker{"Maker": maker, "Keyring": keyring} {
+			store := NewMemoryRevocationStore(WithClock(clock))
+			revocable := NewRevocableMaker(wrapped, store)
+
+			token, _, err := revocable.CreateToken("alice", time.Minute)
+			require.NoError(t, err, name)
+			require.NoError(t, revocable.RevokeToken(token), name)
+
+			// expired, but still accepted by the maker
+			now = now.Add(90 * time.Second)
+			_, err = wrapped.VerifyToken(token)
+			require.NoError(t, err, name)
+
+			_, err = revocable.VerifyToken(token)
+			require.ErrorIs(t, err, ErrRevokedToken, name)
+			require.Equal(t, 1, store.Len(), name)
+
+			// the store sweeps by the maker's clock, once the leeway has passed as well
+			now = now.Add(time.Minute)
+			require.Zero(t, store.Len(), name)
+		}
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		maker, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+		revocable := NewRevocableMaker(maker, NewMemoryRevocationStore())
+
+		token, _, err := revocable.CreateToken("alice", -time.Minute)
+		require.NoError(t, err)
+
+		_, err = revocable.VerifyToken(token)
+		require.ErrorIs(t, err, ErrExpiredToken)
+		require.ErrorIs(t, revocable.RevokeToken(token), ErrExpiredToken)
+	})
+}
@@ -0,0 +1,124 @@
+package token
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInsufficientScope is returned when a valid token lacks the scopes or roles a request needs
+var ErrInsufficientScope = errors.New("token has insufficient scope")
+
+// Scopes holds the "scope" claim. It is encoded as a space-delimited string (RFC 8693 section 4.2),
+// a list of scopes is accepted as well when decoding
+type Scopes []string
+
+// MarshalJSON encodes the scopes as a space-delimited string
+func (scopes Scopes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.Join(scopes, " "))
+}
+
+// UnmarshalJSON accepts either a space-delimited string or a list of scopes
+func (scopes *Scopes) UnmarshalJSON(data []byte) error {
+	var delimited string
+	if err := json.Unmarshal(data, &delimited); err == nil {
+		*scopes = strings.Fields(delimited)
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*scopes = list
+	return nil
+}
+
+// Grants reports whether the scopes grant scope. Scopes are hierarchical, their levels separated by ":".
+// A "*" level in a granted scope matches any one level, and a trailing "*" any number of further levels,
+// so "orders:*" grants "orders:read" and "orders:items:write", and "*" grants every scope
+func (scopes Scopes) Grants(scope string) bool {
+	for _, granted := range scopes {
+		if scopeMatches(granted, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+func scopeMatches(granted, scope string) bool {
+	grantedLevels := strings.Split(granted, ":")
+	levels := strings.Split(scope, ":")
+
+	for i, grantedLevel := range grantedLevels {
+		if i == len(levels) {
+			return false
+		}
+		if grantedLevel == "*" && i == len(grantedLevels)-1 {
+			return true
+		}
+		if grantedLevel != "*" && grantedLevel != levels[i] {
+			return false
+		}
+	}
+	return len(grantedLevels) == len(levels)
+}
+
+// roles holds the "roles" claim. Custom claims may use "roles" for something else than a list of
+// role names, such a claim is left to DecodeClaims instead of failing the whole token
+type roles []string
+
+func (r *roles) UnmarshalJSON(data []byte) error {
+	*r = decodeRoles(data)
+	return nil
+}
+
+// decodeRoles decodes a "roles" claim, nil when it isn't a list of role names
+func decodeRoles(data json.RawMessage) []string {
+	var list []string
+	if len(data) == 0 || json.Unmarshal(data, &list) != nil || len(list) == 0 {
+		return nil
+	}
+	return list
+}
+
+// HasScope reports whether the payload's scopes grant scope (see Scopes.Grants)
+func (payload *Payload) HasScope(scope string) bool {
+	return payload.Scopes.Grants(scope)
+}
+
+// RequireScopes checks that the payload's scopes grant every one of scopes, returning ErrInsufficientScope if not
+func (payload *Payload) RequireScopes(scopes ...string) error {
+	var missing []string
+	for _, scope := range scopes {
+		if !payload.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrInsufficientScope, strings.Join(missing, " "))
+	}
+	return nil
+}
+
+// HasRole reports whether role is one of the payload's roles
+func (payload *Payload) HasRole(role string) bool {
+	for _, candidate := range payload.Roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireAnyRole checks that the payload has at least one of roles, returning ErrInsufficientScope if not
+func (payload *Payload) RequireAnyRole(roles ...string) error {
+	for _, role := range roles {
+		if payload.HasRole(role) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: requires one of the roles %s", ErrInsufficientScope, strings.Join(roles, ", "))
+}
@@ -0,0 +1,91 @@
+package token
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScopesGrants(t *testing.T) {
+	testCases := []struct {
+		granted string
+		scope   string
+		grants  bool
+	}{
+		{"orders:read", "orders:read", true},
+		{"orders:read", "orders:write", false},
+		{"orders:read", "orders", false},
+		{"orders", "orders:read", false},
+		{"orders:*", "orders:read", true},
+		{"orders:*", "orders:items:write", true},
+		{"orders:*", "orders", false},
+		{"orders:*", "invoices:read", false},
+		{"*:read", "orders:read", true},
+		{"*:read", "orders:write", false},
+		{"*:read", "orders:items:read", false},
+		{"orders:*:read", "orders:items:read", true},
+		{"orders:*:read", "orders:items:write", false},
+		{"*", "orders:items:write", true},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.grants, Scopes{tc.granted}.Grants(tc.scope), "%q grants %q", tc.granted, tc.scope)
+	}
+	require.False(t, Scopes(nil).Grants("orders:read"))
+}
+
+func TestScopesJSON(t *testing.T) {
+	data, err := json.Marshal(Scopes{"orders:read", "orders:write"})
+	require.NoError(t, err)
+	require.Equal(t, `"orders:read orders:write"`, string(data))
+
+	var scopes Scopes
+	require.NoError(t, json.Unmarshal([]byte(`" orders:read  orders:write "`), &scopes))
+	require.Equal(t, Scopes{"orders:read", "orders:write"}, scopes)
+
+	require.NoError(t, json.Unmarshal([]byte(`["orders:read", "orders:write"]`), &scopes))
+	require.Equal(t, Scopes{"orders:read", "orders:write"}, scopes)
+
+	require.Error(t, json.Unmarshal([]byte(`42`), &scopes))
+}
+
+func TestPayloadAuthorization(t *testing.T) {
+	payload, err := NewPayload("alice", time.Minute)
+	require.NoError(t, err)
+	payload.Scopes = Scopes{"orders:*", "invoices:read"}
+	payload.Roles = []string{"support"}
+
+	require.NoError(t, payload.RequireScopes())
+	require.NoError(t, payload.RequireScopes("orders:write", "invoices:read"))
+
+	err = payload.RequireScopes("orders:write", "invoices:write", "users:read")
+	require.ErrorIs(t, err, ErrInsufficientScope)
+	require.EqualError(t, err, "token has insufficient scope: missing invoices:write users:read")
+
+	require.True(t, payload.HasRole("support"))
+	require.NoError(t, payload.RequireAnyRole("admin", "support"))
+	require.ErrorIs(t, payload.RequireAnyRole("admin"), ErrInsufficientScope)
+}
+
+func TestScopesAndRolesRoundTrip(t *testing.T) {
+	for name, maker := range newTestMakers(t) {
+		t.Run(name, func(t *testing.T) {
+			payload, err := NewPayload("alice", time.Minute)
+			require.NoError(t, err)
+			payload.Scopes = Scopes{"orders:read", "invoices:*"}
+			payload.Roles = []string{"admin", "support"}
+
+			token, err := maker.(ClaimsMaker).CreateTokenFromPayload(payload)
+			require.NoError(t, err)
+
+			verifiedPayload, err := maker.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.Scopes, verifiedPayload.Scopes)
+			require.Equal(t, payload.Roles, verifiedPayload.Roles)
+			require.NoError(t, verifiedPayload.RequireScopes("invoices:write"))
+			require.NoError(t, verifiedPayload.RequireAnyRole("admin"))
+		})
+	}
+}
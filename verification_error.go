@@ -0,0 +1,152 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// VerificationReason says why VerifyToken rejected a token
+type VerificationReason int
+
+// Reasons a token can fail verification
+const (
+	ReasonMalformed VerificationReason = iota + 1
+	ReasonBadSignature
+	ReasonWrongAlgorithm
+	ReasonExpired
+	ReasonNotYetValid
+	ReasonWrongIssuer
+	ReasonWrongAudience
+	ReasonWrongFooter
+	ReasonRevoked
+	ReasonUnknownKey
+)
+
+var reasonNames = map[VerificationReason]string{
+	ReasonMalformed:      "malformed",
+	ReasonBadSignature:   "bad_signature",
+	ReasonWrongAlgorithm: "wrong_algorithm",
+	ReasonExpired:        "expired",
+	ReasonNotYetValid:    "not_yet_valid",
+	ReasonWrongIssuer:    "wrong_issuer",
+	ReasonWrongAudience:  "wrong_audience",
+	ReasonWrongFooter:    "wrong_footer",
+	ReasonRevoked:        "revoked",
+	ReasonUnknownKey:     "unknown_key",
+}
+
+// String returns the reason in snake case, e.g. "bad_signature", so it can be used as a metric label
+func (reason VerificationReason) String() string {
+	if name, ok := reasonNames[reason]; ok {
+		return name
+	}
+	return fmt.Sprintf("VerificationReason(%d)", int(reason))
+}
+
+// sentinel returns the error a token rejected for reason has always been reported with
+func (reason VerificationReason) sentinel() error {
+	switch reason {
+	case ReasonExpired:
+		return ErrExpiredToken
+	case ReasonNotYetValid:
+		return ErrTokenNotYetValid
+	case ReasonWrongIssuer:
+		return ErrInvalidIssuer
+	case ReasonWrongAudience:
+		return ErrInvalidAudience
+	case ReasonWrongFooter:
+		return ErrInvalidFooter
+	case ReasonRevoked:
+		return ErrRevokedToken
+	case ReasonUnknownKey:
+		return ErrUnknownKeyID
+	default:
+		return ErrInvalidToken
+	}
+}
+
+// VerificationError is returned by every maker's VerifyToken when it rejects a token.
+// It unwraps to the matching sentinel error, so errors.Is(err, ErrExpiredToken) keeps working,
+// while errors.As gives access to the reason
+type VerificationError struct {
+	Reason VerificationReason
+
+	// Err is the sentinel error for the reason, e.g. ErrExpiredToken
+	Err error
+
+	// Cause is the error reported by the underlying token library, if any
+	Cause error
+}
+
+func newVerificationError(reason VerificationReason, cause error) *VerificationError {
+	return &VerificationError{Reason: reason, Err: reason.sentinel(), Cause: cause}
+}
+
+func (e *VerificationError) Error() string {
+	if e.Cause == nil {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ": " + e.Cause.Error()
+}
+
+func (e *VerificationError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// VerificationReasonOf returns why err's token was rejected, or 0 when err isn't a VerificationError
+func VerificationReasonOf(err error) VerificationReason {
+	var verificationErr *VerificationError
+	if errors.As(err, &verificationErr) {
+		return verificationErr.Reason
+	}
+	return 0
+}
+
+// IsTokenRejected reports whether err says the token itself was rejected, e.g. because it is malformed, expired
+// or revoked, rather than that it couldn't be verified, e.g. because a store is down or the context was cancelled
+func IsTokenRejected(err error) bool {
+	return errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrExpiredToken) || VerificationReasonOf(err) != 0
+}
+
+// jwtVerificationError converts an error returned by jwt-go's parser
+func jwtVerificationError(err error) error {
+	var validationErr *jwt.ValidationError
+	if !errors.As(err, &validationErr) {
+		return newVerificationError(ReasonMalformed, err)
+	}
+
+	// the key function's own errors already say what went wrong
+	var verificationErr *VerificationError
+	if errors.As(validationErr.Inner, &verificationErr) {
+		return verificationErr
+	}
+
+	switch {
+	case validationErr.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return newVerificationError(ReasonBadSignature, err)
+	case validationErr.Errors&jwt.ValidationErrorUnverifiable != 0:
+		// jwt-go doesn't know the token's "alg"
+		return newVerificationError(ReasonWrongAlgorithm, err)
+	default:
+		return newVerificationError(ReasonMalformed, err)
+	}
+}
+
+// pasetoVerificationError converts an error returned by go-paseto's parser. go-paseto doesn't export its
+// error values, so failed signature and MAC checks are told apart from malformed tokens by their message
+func pasetoVerificationError(err error) error {
+	reason := ReasonMalformed
+	message := err.Error()
+	if strings.Contains(message, "bad signature") ||
+		strings.Contains(message, "bad message authentication code") ||
+		strings.Contains(message, "message authentication failed") {
+		reason = ReasonBadSignature
+	}
+	return newVerificationError(reason, fmt.Errorf("could not parse payload: %w", err))
+}
@@ -0,0 +1,160 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+)
+
+// tamperToken flips a character in the middle of the token's signature, MAC or ciphertext
+func tamperToken(token string) string {
+	parts := strings.Split(token, ".")
+	index := 2
+	if len(parts) == 5 {
+		index = 3
+	}
+
+	part := []byte(parts[index])
+	middle := len(part) / 2
+	if part[middle] == 'A' {
+		part[middle] = 'B'
+	} else {
+		part[middle] = 'A'
+	}
+	parts[index] = string(part)
+	return strings.Join(parts, ".")
+}
+
+func TestVerificationError(t *testing.T) {
+	for name, maker := range newTestMakers(t, WithExpectedAudience("orders")) {
+		t.Run(name, func(t *testing.T) {
+			claimsMaker, ok := maker.(ClaimsMaker)
+			require.True(t, ok)
+
+			newToken := func(t *testing.T, build func(payload *Payload)) string {
+				payload, err := NewPayload("alice", time.Minute)
+				require.NoError(t, err)
+				payload.Audience = Audience{"orders"}
+				build(payload)
+
+				token, err := claimsMaker.CreateTokenFromPayload(payload)
+				require.NoError(t, err)
+				return token
+			}
+
+			testCases := []struct {
+				name   string
+				token  string
+				reason VerificationReason
+				err    error
+			}{
+				{
+					name:   "Malformed",
+					token:  "not-a-token",
+					reason: ReasonMalformed,
+					err:    ErrInvalidToken,
+				},
+				{
+					name:   "BadSignature",
+					token:  tamperToken(newToken(t, func(*Payload) {})),
+					reason: ReasonBadSignature,
+					err:    ErrInvalidToken,
+				},
+				{
+					name:   "Expired",
+					token:  newToken(t, func(payload *Payload) { payload.ExpiredAt = time.Now().Add(-time.Minute) }),
+					reason: ReasonExpired,
+					err:    ErrExpiredToken,
+				},
+				{
+					name:   "NotYetValid",
+					token:  newToken(t, func(payload *Payload) { payload.NotBefore = time.Now().Add(time.Minute) }),
+					reason: ReasonNotYetValid,
+					err:    ErrTokenNotYetValid,
+				},
+				{
+					name:   "WrongAudience",
+					token:  newToken(t, func(payload *Payload) { payload.Audience = Audience{"billing"} }),
+					reason: ReasonWrongAudience,
+					err:    ErrInvalidAudience,
+				},
+			}
+
+			for _, tc := range testCases {
+				t.Run(tc.name, func(t *testing.T) {
+					payload, err := maker.VerifyToken(tc.token)
+					require.Nil(t, payload)
+					require.ErrorIs(t, err, tc.err)
+
+					var verificationErr *VerificationError
+					require.True(t, errors.As(err, &verificationErr))
+					require.Equal(t, tc.reason, verificationErr.Reason)
+				})
+			}
+		})
+	}
+
+	t.Run("WrongAlgorithm", func(t *testing.T) {
+		maker, err := NewJWTMaker(randomString(32))
+		require.NoError(t, err)
+		rsaMaker, err := NewRSAJWTMaker("RS256", testRSAKey(), &testRSAKey().PublicKey)
+		require.NoError(t, err)
+
+		token, _, err := rsaMaker.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		_, err = maker.VerifyToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+		require.Equal(t, ReasonWrongAlgorithm, VerificationReasonOf(err))
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		maker, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex())
+		require.NoError(t, err)
+		store := NewMemoryRevocationStore()
+		revocable := NewRevocableMaker(maker, store)
+
+		token, _, err := revocable.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, revocable.RevokeToken(token))
+
+		_, err = revocable.VerifyToken(token)
+		require.ErrorIs(t, err, ErrRevokedToken)
+		require.Equal(t, ReasonRevoked, VerificationReasonOf(err))
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		maker, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex(), WithKeyID("kid-1"))
+		require.NoError(t, err)
+		other, err := NewPasetoV4Local(paseto.NewV4SymmetricKey().ExportHex(), WithKeyID("kid-2"))
+		require.NoError(t, err)
+		keyring, err := NewKeyring(maker)
+		require.NoError(t, err)
+
+		token, _, err := other.CreateToken("alice", time.Minute)
+		require.NoError(t, err)
+
+		_, err = keyring.VerifyToken(token)
+		require.ErrorIs(t, err, ErrUnknownKeyID)
+		require.Equal(t, ReasonUnknownKey, VerificationReasonOf(err))
+	})
+
+	t.Run("ReasonString", func(t *testing.T) {
+		require.Equal(t, "bad_signature", ReasonBadSignature.String())
+		require.Equal(t, "VerificationReason(0)", VerificationReason(0).String())
+		require.Zero(t, VerificationReasonOf(errors.New("boom")))
+	})
+	t.Run("IsTokenRejected", func(t *testing.T) {
+		require.True(t, IsTokenRejected(newVerificationError(ReasonBadSignature, nil)))
+		require.True(t, IsTokenRejected(fmt.Errorf("wrapped: %w", ErrExpiredToken)))
+		require.True(t, IsTokenRejected(ErrRevokedToken))
+		require.False(t, IsTokenRejected(fmt.Errorf("could not check token revocation: %w", context.Canceled)))
+		require.False(t, IsTokenRejected(errors.New("connection refused")))
+	})
+}
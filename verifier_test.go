@@ -0,0 +1,133 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestVerifiers(t *testing.T) {
+	edPublicKey, edPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	v2Secret := paseto.NewV2AsymmetricSecretKey()
+	v3Secret := paseto.NewV3AsymmetricSecretKey()
+	v4Secret := paseto.NewV4AsymmetricSecretKey()
+
+	type pair struct {
+		maker    func() (Maker, error)
+		verifier func() (Maker, error)
+	}
+	pairs := map[string]pair{
+		"AsymJWT": {
+			func() (Maker, error) { return NewAsymJWTMaker(edPrivateKey, edPublicKey) },
+			func() (Maker, error) { return NewAsymJWTVerifier(edPublicKey) },
+		},
+		"RSAJWT": {
+			func() (Maker, error) { return NewRSAJWTMaker("PS256", testRSAKey(), &testRSAKey().PublicKey) },
+			func() (Maker, error) { return NewRSAJWTVerifier("PS256", &testRSAKey().PublicKey) },
+		},
+		"ECDSAJWT": {
+			func() (Maker, error) { return NewECDSAJWTMaker(p256Key, &p256Key.PublicKey) },
+			func() (Maker, error) { return NewECDSAJWTVerifier(&p256Key.PublicKey) },
+		},
+		"PasetoV2Public": {
+			func() (Maker, error) { return NewPasetoV2Public(v2Secret.ExportHex(), v2Secret.Public().ExportHex()) },
+			func() (Maker, error) { return NewPasetoV2PublicVerifier(v2Secret.Public().ExportHex()) },
+		},
+		"PasetoV3Public": {
+			func() (Maker, error) { return NewPasetoV3Public(v3Secret.ExportHex(), v3Secret.Public().ExportHex()) },
+			func() (Maker, error) { return NewPasetoV3PublicVerifier(v3Secret.Public().ExportHex()) },
+		},
+		"PasetoV4Public": {
+			func() (Maker, error) { return NewPasetoV4Public(v4Secret.ExportHex(), v4Secret.Public().ExportHex()) },
+			func() (Maker, error) { return NewPasetoV4PublicVerifier(v4Secret.Public().ExportHex()) },
+		},
+	}
+
+	for name, pair := range pairs {
+		t.Run(name, func(t *testing.T) {
+			maker, err := pair.maker()
+			require.NoError(t, err)
+			verifier, err := pair.verifier()
+			require.NoError(t, err)
+
+			var signer Signer = maker
+			token, payload, err := signer.CreateToken("alice", time.Minute)
+			require.NoError(t, err)
+
+			var tokenVerifier Verifier = verifier
+			verifiedPayload, err := tokenVerifier.VerifyToken(token)
+			require.NoError(t, err)
+			require.Equal(t, payload.ID, verifiedPayload.ID)
+
+			_, _, err = verifier.CreateToken("alice", time.Minute)
+			require.ErrorIs(t, err, ErrVerificationOnly)
+			_, err = verifier.(ClaimsMaker).CreateTokenFromPayload(payload)
+			require.ErrorIs(t, err, ErrVerificationOnly)
+
+			format, err := DetectTokenFormat(token)
+			require.NoError(t, err)
+			require.Equal(t, format, verifier.(FormatMaker).TokenFormat())
+		})
+	}
+
+	t.Run("PASERK", func(t *testing.T) {
+		maker, err := NewPasetoV4Public(v4Secret.ExportHex(), v4Secret.Public().ExportHex())
+		require.NoError(t, err)
+		verifier, err := NewPasetoV4PublicVerifierFromPASERK(maker.PublicPASERK(), WithPASERKKeyID())
+		require.NoError(t, err)
+
+		require.Equal(t, maker.PASERKID(), verifier.PASERKID())
+		require.Empty(t, verifier.SecretPASERK())
+		require.Empty(t, verifier.SecretPASERKID())
+
+		_, err = NewPasetoV4PublicVerifierFromPASERK(maker.SecretPASERK())
+		require.Error(t, err)
+	})
+}
+
+func TestKeyPairMismatch(t *testing.T) {
+	_, otherEdPrivateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = NewAsymJWTMaker(otherEdPrivateKey, edPublicKey)
+	require.ErrorIs(t, err, ErrKeyMismatch)
+	_, err = NewAsymJWTMaker(nil, edPublicKey)
+	require.Error(t, err)
+	_, err = NewAsymJWTVerifier(edPublicKey[:16])
+	require.Error(t, err)
+
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, err = NewRSAJWTMaker("RS256", testRSAKey(), &otherRSAKey.PublicKey)
+	require.ErrorIs(t, err, ErrKeyMismatch)
+	_, err = NewRSAJWTVerifier("RS256", nil)
+	require.Error(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	otherP256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = NewECDSAJWTMaker(p256Key, &otherP256Key.PublicKey)
+	require.ErrorIs(t, err, ErrKeyMismatch)
+
+	v2Secret := paseto.NewV2AsymmetricSecretKey()
+	_, err = NewPasetoV2Public(v2Secret.ExportHex(), paseto.NewV2AsymmetricSecretKey().Public().ExportHex())
+	require.ErrorIs(t, err, ErrKeyMismatch)
+	v3Secret := paseto.NewV3AsymmetricSecretKey()
+	_, err = NewPasetoV3Public(v3Secret.ExportHex(), paseto.NewV3AsymmetricSecretKey().Public().ExportHex())
+	require.ErrorIs(t, err, ErrKeyMismatch)
+	v4Secret := paseto.NewV4AsymmetricSecretKey()
+	_, err = NewPasetoV4PublicFromBytes(v4Secret.ExportBytes(), paseto.NewV4AsymmetricSecretKey().Public().ExportBytes())
+	require.ErrorIs(t, err, ErrKeyMismatch)
+}